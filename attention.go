@@ -0,0 +1,62 @@
+package clt
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+// AttentionLevel is how strongly Attention tries to draw the user back to the terminal
+type AttentionLevel int
+
+// Attention levels
+const (
+	// AttentionBell rings the terminal bell
+	AttentionBell AttentionLevel = iota
+	// AttentionFlash briefly flashes the screen as well as ringing the bell
+	AttentionFlash
+	// AttentionUrgent also asks the desktop to flag the terminal window, such as by
+	// bouncing its dock icon or flashing its taskbar button
+	AttentionUrgent
+)
+
+// flashDuration is how long the screen stays inverted for AttentionFlash
+var flashDuration = 100 * time.Millisecond
+
+// Attention draws the user back to the terminal, such as when a prompt has been
+// waiting for an answer.  It does nothing when standard output isn't a terminal.
+// Terminals that don't support a level ignore the parts they don't understand.
+func Attention(level AttentionLevel) {
+	if !isTerminal(os.Stdout) {
+		return
+	}
+	attention(os.Stdout, level, os.Getenv)
+}
+
+// AttentionAfter calls Attention once d has passed, unless the returned function is
+// called first.  Start it before asking a question and cancel it once it is answered.
+func AttentionAfter(d time.Duration, level AttentionLevel) (cancel func()) {
+	t := time.AfterFunc(d, func() { Attention(level) })
+	return func() { t.Stop() }
+}
+
+func attention(w io.Writer, level AttentionLevel, getenv func(string) string) {
+	fmt.Fprint(w, "\a")
+	if level >= AttentionFlash {
+		// DECSCNM switches the screen to reverse video and back
+		fmt.Fprint(w, "\x1b[?5h")
+		time.Sleep(flashDuration)
+		fmt.Fprint(w, "\x1b[?5l")
+	}
+	if level >= AttentionUrgent {
+		switch {
+		case getenv("TERM_PROGRAM") == "iTerm.app":
+			// bounces the dock icon
+			fmt.Fprint(w, "\x1b]1337;RequestAttention=yes\x07")
+		default:
+			// other terminals flag their window for the bell, if at all
+			flashWindow()
+		}
+	}
+}
@@ -0,0 +1,7 @@
+//go:build !windows
+
+package clt
+
+// flashWindow does nothing outside Windows, where terminals decide for themselves
+// whether the bell flags their window
+func flashWindow() {}
@@ -0,0 +1,33 @@
+package clt
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestAttention(t *testing.T) {
+	defer func(d time.Duration) { flashDuration = d }(flashDuration)
+	flashDuration = 0
+
+	tt := []struct {
+		Name  string
+		Level AttentionLevel
+		Env   map[string]string
+		Want  string
+	}{
+		{Name: "bell", Level: AttentionBell, Want: "\a"},
+		{Name: "flash", Level: AttentionFlash, Want: "\a\x1b[?5h\x1b[?5l"},
+		{Name: "urgent", Level: AttentionUrgent, Want: "\a\x1b[?5h\x1b[?5l"},
+		{Name: "urgent iterm", Level: AttentionUrgent, Env: map[string]string{"TERM_PROGRAM": "iTerm.app"}, Want: "\a\x1b[?5h\x1b[?5l\x1b]1337;RequestAttention=yes\x07"},
+	}
+	for _, tc := range tt {
+		t.Run(tc.Name, func(t *testing.T) {
+			var out bytes.Buffer
+			attention(&out, tc.Level, func(key string) string { return tc.Env[key] })
+			if out.String() != tc.Want {
+				t.Errorf("expected %q, got %q", tc.Want, out.String())
+			}
+		})
+	}
+}
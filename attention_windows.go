@@ -0,0 +1,40 @@
+//go:build windows
+
+package clt
+
+import (
+	"syscall"
+	"unsafe"
+)
+
+var (
+	getConsoleWindow = syscall.NewLazyDLL("kernel32.dll").NewProc("GetConsoleWindow")
+	flashWindowEx    = syscall.NewLazyDLL("user32.dll").NewProc("FlashWindowEx")
+)
+
+// flashWInfo is FLASHWINFO, which describes how FlashWindowEx flashes a window
+type flashWInfo struct {
+	size    uint32
+	hwnd    uintptr
+	flags   uint32
+	count   uint32
+	timeout uint32
+}
+
+// flashAll and flashUntilForeground are the FLASHW_ALL and FLASHW_TIMERNOFG flags
+const (
+	flashAll             = 0x3
+	flashUntilForeground = 0xc
+)
+
+// flashWindow flashes the taskbar button of the console window until it is brought
+// to the foreground
+func flashWindow() {
+	hwnd, _, _ := getConsoleWindow.Call()
+	if hwnd == 0 {
+		return
+	}
+	info := flashWInfo{hwnd: hwnd, flags: flashAll | flashUntilForeground}
+	info.size = uint32(unsafe.Sizeof(info))
+	flashWindowEx.Call(uintptr(unsafe.Pointer(&info)))
+}
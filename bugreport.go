@@ -0,0 +1,156 @@
+package clt
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/ssh/terminal"
+)
+
+// BugReportOptions describes what goes into a report written by BugReport
+type BugReportOptions struct {
+	// Tool and Version identify the program the report is for
+	Tool    string
+	Version string
+	// Comment is the user's description of the problem
+	Comment string
+	// Recorder supplies the recent terminal output for the report
+	Recorder *Recorder
+	// Lines is the number of recent lines of output to include.  Defaults to 200.
+	Lines int
+	// Sections are extra named blocks of text, such as a config dump
+	Sections map[string]string
+	// Path is the file that is written.  A path ending in .zip writes an archive with
+	// the report and the full transcript, anything else writes a text file.  Defaults
+	// to a timestamped text file in the temporary directory.
+	Path string
+}
+
+// BugReport writes a report that a user can attach to an issue and returns the path
+// to it.  It gathers terminal capabilities, OS information, recently rendered output,
+// and the user's comment.  Registered secrets and the user's home directory are
+// redacted from everything in the report.
+func BugReport(opts BugReportOptions) (string, error) {
+	path := opts.Path
+	if len(path) == 0 {
+		name := opts.Tool
+		if len(name) == 0 {
+			name = filepath.Base(os.Args[0])
+		}
+		path = filepath.Join(os.TempDir(), fmt.Sprintf("%s-bug-report-%s.txt", name, time.Now().Format("20060102-150405")))
+	}
+
+	report := bugReportText(opts)
+	if !strings.HasSuffix(strings.ToLower(path), ".zip") {
+		return path, os.WriteFile(path, []byte(report), 0600)
+	}
+
+	var buf bytes.Buffer
+	z := zip.NewWriter(&buf)
+	files := []struct{ name, body string }{{"report.txt", report}}
+	if opts.Recorder != nil {
+		files = append(files, struct{ name, body string }{"transcript.txt", redactReport(opts.Recorder.Transcript())})
+	}
+	for _, f := range files {
+		w, err := z.Create(f.name)
+		if err != nil {
+			return "", err
+		}
+		if _, err := w.Write([]byte(f.body)); err != nil {
+			return "", err
+		}
+	}
+	if err := z.Close(); err != nil {
+		return "", err
+	}
+	return path, os.WriteFile(path, buf.Bytes(), 0600)
+}
+
+// bugReportText renders the text of the report
+func bugReportText(opts BugReportOptions) string {
+	var b strings.Builder
+	section := func(title string, body string) {
+		fmt.Fprintf(&b, "## %s\n\n%s\n\n", title, strings.TrimRight(body, "\n"))
+	}
+
+	title := "Bug report"
+	if len(opts.Tool) > 0 {
+		title = fmt.Sprintf("Bug report for %s %s", opts.Tool, opts.Version)
+	}
+	fmt.Fprintf(&b, "# %s\n\n", strings.TrimSpace(title))
+
+	comment := strings.TrimSpace(opts.Comment)
+	if len(comment) == 0 {
+		comment = "(none)"
+	}
+	section("Comment", comment)
+	section("System", fmt.Sprintf("OS: %s/%s\nGo: %s\nTime: %s", runtime.GOOS, runtime.GOARCH, runtime.Version(), time.Now().UTC().Format(time.RFC3339)))
+	section("Terminal", terminalCapabilities())
+	if opts.Recorder != nil {
+		lines := opts.Lines
+		if lines <= 0 {
+			lines = 200
+		}
+		section("Recent output", recentOutput(opts.Recorder.Transcript(), lines))
+	}
+
+	var names []string
+	for name := range opts.Sections {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		section(name, opts.Sections[name])
+	}
+	return redactReport(b.String())
+}
+
+// terminalCapabilities describes the terminal attached to stdout
+func terminalCapabilities() string {
+	console.RLock()
+	ansi := console.ansi
+	console.RUnlock()
+
+	tty := terminal.IsTerminal(int(os.Stdout.Fd()))
+	lines := []string{
+		fmt.Sprintf("Stdout is a terminal: %t", tty),
+		fmt.Sprintf("Width: %d", terminalWidth(os.Stdout)),
+		fmt.Sprintf("ANSI escape sequences: %t", ansi),
+	}
+	for _, env := range []string{"TERM", "COLORTERM", "TERM_PROGRAM", "LANG", "NO_COLOR"} {
+		if v, ok := os.LookupEnv(env); ok {
+			lines = append(lines, fmt.Sprintf("%s=%s", env, v))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// recentOutput returns the last n lines of a transcript with escape sequences and
+// carriage returns made visible, so that redrawn frames can be told apart
+func recentOutput(transcript string, n int) string {
+	lines := strings.Split(strings.TrimRight(transcript, "\n"), "\n")
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	visible := strings.NewReplacer("\x1b", `\x1b`, "\r", `\r`)
+	for i, l := range lines {
+		lines[i] = visible.Replace(l)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// redactReport removes registered secrets and the user's home directory from s
+func redactReport(s string) string {
+	s = Redact(s)
+	if home, err := os.UserHomeDir(); err == nil && len(home) > 1 {
+		s = strings.Replace(s, home, "~", -1)
+	}
+	return s
+}
@@ -0,0 +1,63 @@
+package clt
+
+import (
+	"archive/zip"
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestBugReport(t *testing.T) {
+	withSecrets(t)
+	rec := NewRecorder(bytes.NewBuffer(nil))
+	RegisterSecret("hunter2-token")
+	rec.Write([]byte("Logging in with hunter2-token\n\x1b[?25l\rWorking: [=====     ]\n"))
+
+	path, err := BugReport(BugReportOptions{
+		Tool:     "mytool",
+		Version:  "1.2.0",
+		Comment:  "The bar drew twice",
+		Recorder: rec,
+		Path:     filepath.Join(t.TempDir(), "report.txt"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	report := string(data)
+	for _, want := range []string{"# Bug report for mytool 1.2.0", "The bar drew twice", "## Terminal", "Logging in with " + Mask, `\x1b[?25l\rWorking`} {
+		if !strings.Contains(report, want) {
+			t.Errorf("Expected report to contain %q, got:\n%s", want, report)
+		}
+	}
+	if strings.Contains(report, "hunter2-token") {
+		t.Errorf("Expected secrets to be redacted from the report")
+	}
+}
+
+func TestBugReportZip(t *testing.T) {
+	rec := NewRecorder(nil)
+	rec.Write([]byte("output\n"))
+
+	path, err := BugReport(BugReportOptions{Recorder: rec, Path: filepath.Join(t.TempDir(), "report.zip")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	z, err := zip.OpenReader(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer z.Close()
+	var names []string
+	for _, f := range z.File {
+		names = append(names, f.Name)
+	}
+	if got := strings.Join(names, ","); got != "report.txt,transcript.txt" {
+		t.Errorf("Expected report.txt and transcript.txt in the archive, got %s", got)
+	}
+}
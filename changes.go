@@ -0,0 +1,215 @@
+package clt
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// ChangeKind is the type of a change between two configurations
+type ChangeKind int
+
+// Kinds of change
+const (
+	Added ChangeKind = iota
+	Removed
+	Modified
+)
+
+// Change is a setting that differs between two configurations.  Old is empty for
+// added settings and New is empty for removed ones.
+type Change struct {
+	Key  string
+	Kind ChangeKind
+	Old  string
+	New  string
+}
+
+// Diff compares two configurations and returns the settings that were added, removed
+// or modified, sorted by key.  before and after can be maps with string keys or
+// structs, and nested maps and structs are compared setting by setting with keys
+// joined by dots.  Secrets are compared by value but are always shown masked.
+func Diff(before interface{}, after interface{}) []Change {
+	old, updated := map[string]setting{}, map[string]setting{}
+	flatten("", reflect.ValueOf(before), old)
+	flatten("", reflect.ValueOf(after), updated)
+
+	var changes []Change
+	for k, v := range old {
+		n, ok := updated[k]
+		switch {
+		case !ok:
+			changes = append(changes, Change{Key: k, Kind: Removed, Old: v.shown})
+		case n.value != v.value:
+			changes = append(changes, Change{Key: k, Kind: Modified, Old: v.shown, New: n.shown})
+		}
+	}
+	for k, v := range updated {
+		if _, ok := old[k]; !ok {
+			changes = append(changes, Change{Key: k, Kind: Added, New: v.shown})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Key < changes[j].Key })
+	return changes
+}
+
+// setting is a value to compare and how it is shown, which differ for secrets
+type setting struct {
+	value string
+	shown string
+}
+
+// flatten adds every setting in v to values, keyed by its path from prefix
+func flatten(prefix string, v reflect.Value, values map[string]setting) {
+	for v.IsValid() && (v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface) {
+		if v.IsNil() {
+			return
+		}
+		v = v.Elem()
+	}
+	join := func(key string) string {
+		if len(prefix) == 0 {
+			return key
+		}
+		return prefix + "." + key
+	}
+
+	switch {
+	case !v.IsValid():
+		return
+	case v.Kind() == reflect.Map && v.Type().Key().Kind() == reflect.String:
+		for _, k := range v.MapKeys() {
+			flatten(join(k.String()), v.MapIndex(k), values)
+		}
+	case v.Kind() == reflect.Struct && !v.Type().Implements(stringerType):
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			if t.Field(i).PkgPath != "" {
+				// unexported
+				continue
+			}
+			flatten(join(t.Field(i).Name), v.Field(i), values)
+		}
+	default:
+		// secrets format as Mask, so they are compared by their value
+		s := setting{shown: fmt.Sprint(v.Interface())}
+		s.value = s.shown
+		if secret, ok := v.Interface().(Secret); ok {
+			s.value = secret.Reveal()
+		}
+		values[prefix] = s
+	}
+}
+
+var stringerType = reflect.TypeOf((*fmt.Stringer)(nil)).Elem()
+
+// RenderChanges returns a summary of changes for plan style commands, with a line for
+// each setting aligned by key, followed by a count of each kind of change.  Added
+// settings are green, removed settings are red, and modified settings are yellow and
+// show the old and new values.
+func RenderChanges(changes []Change) string {
+	if len(changes) == 0 {
+		return "No changes.\n"
+	}
+	return strings.Join(changeLines(changes), "\n") + "\n\n" + changeSummary(changes) + "\n"
+}
+
+// changeLines returns one styled line per change with the keys aligned
+func changeLines(changes []Change) []string {
+	width := 0
+	for _, c := range changes {
+		if n := displayWidth(c.Key); n > width {
+			width = n
+		}
+	}
+
+	var lines []string
+	for _, c := range changes {
+		key := c.Key + strings.Repeat(" ", width-displayWidth(c.Key))
+		switch c.Kind {
+		case Added:
+			lines = append(lines, CurrentTheme().Success.ApplyTo(fmt.Sprintf("  + %s = %s", key, c.New)))
+		case Removed:
+			lines = append(lines, CurrentTheme().Error.ApplyTo(fmt.Sprintf("  - %s = %s", key, c.Old)))
+		case Modified:
+			lines = append(lines, CurrentTheme().Warning.ApplyTo(fmt.Sprintf("  ~ %s = %s → %s", key, c.Old, c.New)))
+		}
+	}
+	return lines
+}
+
+// changeSummary counts the changes of each kind
+func changeSummary(changes []Change) string {
+	var added, removed, modified int
+	for _, c := range changes {
+		switch c.Kind {
+		case Added:
+			added++
+		case Removed:
+			removed++
+		case Modified:
+			modified++
+		}
+	}
+	return fmt.Sprintf("%d to add, %d to change, %d to remove.", added, modified, removed)
+}
+
+// ConfirmChanges shows the changes from before to after and asks the user whether to
+// apply them, defaulting to no.  It returns false without asking if nothing changed.
+// Changes that don't fit on the terminal are shown a page at a time, and the summary
+// and the question always follow the last page so they can't scroll out of view.
+func ConfirmChanges(before interface{}, after interface{}) bool {
+	return confirmChanges(os.Stdin, os.Stdout, Diff(before, after), changesPageSize(os.Stdout))
+}
+
+// changesPageSize is the number of changes shown per page on w, or 0 if w isn't a
+// terminal and everything is shown at once
+func changesPageSize(w io.Writer) int {
+	// leave room for the more prompt, the summary, and the question
+	if height := terminalHeight(w); height > 6 {
+		return height - 6
+	}
+	return 0
+}
+
+func confirmChanges(in io.Reader, out io.Writer, changes []Change, pageSize int) bool {
+	if len(changes) == 0 {
+		fmt.Fprintf(out, "\n%s", RenderChanges(changes))
+		return false
+	}
+	// one reader is shared by every prompt so that buffered answers aren't lost
+	r := bufio.NewReader(in)
+	fmt.Fprintln(out)
+	lines := changeLines(changes)
+	for start := 0; start < len(lines); start += pageSize {
+		if pageSize <= 0 {
+			fmt.Fprintf(out, "%s\n", strings.Join(lines, "\n"))
+			break
+		}
+		end := start + pageSize
+		if end > len(lines) {
+			end = len(lines)
+		}
+		fmt.Fprintf(out, "%s\n", strings.Join(lines[start:end], "\n"))
+		if end == len(lines) {
+			break
+		}
+		fmt.Fprintf(out, "-- %d of %d -- Press [Enter] for more, [a] to show the rest, or [s] to skip to the question: ", end, len(lines))
+		resp, err := r.ReadString('\n')
+		resp = strings.ToLower(strings.TrimSpace(resp))
+		switch {
+		case resp == "s" || (err != nil && len(resp) == 0):
+			fmt.Fprintf(out, "... %d more not shown\n", len(lines)-end)
+			start = len(lines)
+		case resp == "a":
+			fmt.Fprintf(out, "%s\n", strings.Join(lines[end:], "\n"))
+			start = len(lines)
+		}
+	}
+	fmt.Fprintf(out, "\n%s\n", changeSummary(changes))
+	return confirmPrompt(r, out, "Apply these changes?", false)
+}
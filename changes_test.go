@@ -0,0 +1,95 @@
+package clt
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	type db struct {
+		Host     string
+		Password Secret
+	}
+	type config struct {
+		Region  string
+		DB      db
+		Tags    map[string]string
+		private string
+	}
+	before := config{Region: "us-east-1", DB: db{"db1", "old"}, Tags: map[string]string{"team": "infra", "env": "dev"}}
+	after := config{Region: "eu-west-1", DB: db{"db1", "new"}, Tags: map[string]string{"team": "infra", "owner": "sam"}, private: "ignored"}
+
+	want := []Change{
+		{Key: "DB.Password", Kind: Modified, Old: Mask, New: Mask},
+		{Key: "Region", Kind: Modified, Old: "us-east-1", New: "eu-west-1"},
+		{Key: "Tags.env", Kind: Removed, Old: "dev"},
+		{Key: "Tags.owner", Kind: Added, New: "sam"},
+	}
+	if got := Diff(before, after); !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %+v, got %+v", want, got)
+	}
+}
+
+func TestRenderChanges(t *testing.T) {
+	got := RenderChanges([]Change{
+		{Key: "region", Kind: Modified, Old: "us-east-1", New: "eu-west-1"},
+		{Key: "replicas", Kind: Added, New: "3"},
+		{Key: "ttl", Kind: Removed, Old: "60"},
+	})
+	want := "\x1b[33m  ~ region   = us-east-1 → eu-west-1\x1b[39m\n" +
+		"\x1b[32m  + replicas = 3\x1b[39m\n" +
+		"\x1b[31m  - ttl      = 60\x1b[39m\n" +
+		"\n1 to add, 1 to change, 1 to remove.\n"
+	if got != want {
+		t.Errorf("Expected:\n%s\ngot:\n%s", want, got)
+	}
+}
+
+func TestConfirmChanges(t *testing.T) {
+	out := bytes.NewBuffer(nil)
+	changes := Diff(map[string]int{"replicas": 2}, map[string]int{"replicas": 3})
+	if !confirmChanges(strings.NewReader("y\n"), out, changes, 0) {
+		t.Errorf("Expected the changes to be confirmed")
+	}
+	if !strings.Contains(out.String(), "replicas = 2 → 3") || !strings.Contains(out.String(), "Apply these changes?") {
+		t.Errorf("Expected the summary and prompt, got %q", out.String())
+	}
+
+	out.Reset()
+	if confirmChanges(strings.NewReader("y\n"), out, nil, 0) || out.String() != "\nNo changes.\n" {
+		t.Errorf("Expected no prompt without changes, got %q", out.String())
+	}
+}
+
+func TestConfirmChangesPaged(t *testing.T) {
+	before, after := map[string]int{}, map[string]int{}
+	for i := 0; i < 7; i++ {
+		after[fmt.Sprintf("key%d", i)] = i
+	}
+	changes := Diff(before, after)
+
+	out := bytes.NewBuffer(nil)
+	if !confirmChanges(strings.NewReader("\n\ny\n"), out, changes, 3) {
+		t.Errorf("Expected the changes to be confirmed after paging")
+	}
+	got := out.String()
+	for _, want := range []string{"-- 3 of 7 --", "-- 6 of 7 --", "key6 = 6"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Expected output to contain %q, got %q", want, got)
+		}
+	}
+	if !strings.HasSuffix(got, "0 to remove.\n\nApply these changes?  [y/N]: ") {
+		t.Errorf("Expected the question right after the last page, got %q", got)
+	}
+
+	out.Reset()
+	if confirmChanges(strings.NewReader("s\nn\n"), out, changes, 3) {
+		t.Errorf("Expected the changes to be rejected")
+	}
+	if !strings.Contains(out.String(), "... 4 more not shown") || strings.Contains(out.String(), "key6") {
+		t.Errorf("Expected skipping to jump to the question, got %q", out.String())
+	}
+}
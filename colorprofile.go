@@ -0,0 +1,125 @@
+package clt
+
+import (
+	"os"
+	"runtime"
+	"strings"
+)
+
+// ColorDepth is how many colors a terminal can show, see ColorProfile
+type ColorDepth int
+
+const (
+	// NoColor is for output that shouldn't be styled at all, see ForceColor
+	NoColor ColorDepth = iota
+	// ANSI16 is the basic colors and their bright versions
+	ANSI16
+	// ANSI256 is the xterm palette of Color256
+	ANSI256
+	// TrueColor is any 24-bit color from RGB
+	TrueColor
+)
+
+func (d ColorDepth) String() string {
+	switch d {
+	case NoColor:
+		return "none"
+	case ANSI16:
+		return "16 colors"
+	case ANSI256:
+		return "256 colors"
+	case TrueColor:
+		return "truecolor"
+	}
+	return "unknown"
+}
+
+// ColorProfile returns how many colors the terminal can show.  Styles with colors
+// beyond it are given the nearest color it has instead.  It is NoColor when styles
+// are turned off, see ForceColor, and ANSI16 on a Windows console that can't
+// interpret escape sequences, where styles are shown with the colors of the console.
+func ColorProfile() ColorDepth {
+	console.RLock()
+	ansi := console.ansi
+	console.RUnlock()
+	switch {
+	case !colorEnabled():
+		return NoColor
+	case !ansi:
+		return ANSI16
+	}
+	return detectColorProfile(os.Getenv, runtime.GOOS == "windows")
+}
+
+// trueColorPrograms are the values of TERM_PROGRAM for terminals that show truecolor
+// without setting COLORTERM
+var trueColorPrograms = []string{"iTerm.app", "WezTerm", "vscode"}
+
+// detectColorProfile returns the colors of a terminal that interprets escape
+// sequences, from COLORTERM, TERM and the name of the terminal program
+func detectColorProfile(getenv func(string) string, windows bool) ColorDepth {
+	term := getenv("TERM")
+	switch {
+	case getenv("COLORTERM") == "truecolor", getenv("COLORTERM") == "24bit":
+		return TrueColor
+	case strings.HasSuffix(term, "-direct"), strings.Contains(term, "truecolor"), strings.Contains(term, "24bit"):
+		return TrueColor
+	case len(getenv("WT_SESSION")) > 0:
+		return TrueColor
+	}
+	for _, program := range trueColorPrograms {
+		if getenv("TERM_PROGRAM") == program {
+			return TrueColor
+		}
+	}
+	switch {
+	case strings.Contains(term, "256color"):
+		return ANSI256
+	case windows && len(term) == 0:
+		// consoles on Windows 10 and later show truecolor once escape sequences are
+		// turned on
+		return TrueColor
+	}
+	return ANSI16
+}
+
+// attributeSupport records which of the less common textstyles a terminal shows
+type attributeSupport struct {
+	italic, strikethrough, overline bool
+}
+
+// terminalAttributes returns the textstyles that the terminal shows
+func terminalAttributes() attributeSupport {
+	console.RLock()
+	ansi := console.ansi
+	console.RUnlock()
+	return detectAttributes(os.Getenv, ansi)
+}
+
+// detectAttributes returns the textstyles shown by a terminal, from TERM and the name
+// of the terminal program.  ansi is false for a Windows console that can't interpret
+// escape sequences.
+func detectAttributes(getenv func(string) string, ansi bool) attributeSupport {
+	if !ansi {
+		return attributeSupport{}
+	}
+	a := attributeSupport{italic: true, strikethrough: true, overline: true}
+	term := getenv("TERM")
+	switch {
+	case term == "linux":
+		// the Linux console shows none of them
+		a = attributeSupport{}
+	case term == "screen" || strings.HasPrefix(term, "screen-") || strings.HasPrefix(term, "screen."):
+		// screen, and tmux claiming to be screen, show italic as reverse video
+		a.italic = false
+		a.overline = false
+	}
+	if getenv("TERM_PROGRAM") == "Apple_Terminal" {
+		a.strikethrough = false
+		a.overline = false
+	}
+	return a
+}
+
+// allAttributes is a terminal that shows every textstyle
+var allAttributes = attributeSupport{italic: true, strikethrough: true, overline: true}
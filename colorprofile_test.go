@@ -0,0 +1,55 @@
+package clt
+
+import (
+	"bytes"
+	"io"
+	"testing"
+)
+
+func TestDetectColorProfile(t *testing.T) {
+	tt := []struct {
+		Name    string
+		Env     map[string]string
+		Windows bool
+		Want    ColorDepth
+	}{
+		{Name: "colorterm", Env: map[string]string{"COLORTERM": "truecolor", "TERM": "xterm"}, Want: TrueColor},
+		{Name: "direct", Env: map[string]string{"TERM": "xterm-direct"}, Want: TrueColor},
+		{Name: "windows terminal", Env: map[string]string{"WT_SESSION": "1"}, Want: TrueColor},
+		{Name: "iterm", Env: map[string]string{"TERM_PROGRAM": "iTerm.app", "TERM": "xterm-256color"}, Want: TrueColor},
+		{Name: "256 colors", Env: map[string]string{"TERM": "xterm-256color"}, Want: ANSI256},
+		{Name: "basic", Env: map[string]string{"TERM": "xterm"}, Want: ANSI16},
+		{Name: "windows console", Windows: true, Want: TrueColor},
+		{Name: "windows ssh", Env: map[string]string{"TERM": "xterm"}, Windows: true, Want: ANSI16},
+	}
+	for _, tc := range tt {
+		t.Run(tc.Name, func(t *testing.T) {
+			if got := detectColorProfile(func(key string) string { return tc.Env[key] }, tc.Windows); got != tc.Want {
+				t.Errorf("Expected %s, got %s", tc.Want, got)
+			}
+		})
+	}
+}
+
+func TestColorProfile(t *testing.T) {
+	defer ForceColor(true)
+	ForceColor(false)
+	if got := ColorProfile(); got != NoColor {
+		t.Errorf("Expected no color when styles are off, got %s", got)
+	}
+	ForceColor(true)
+	for _, key := range []string{"COLORTERM", "WT_SESSION", "TERM_PROGRAM"} {
+		t.Setenv(key, "")
+	}
+	t.Setenv("TERM", "xterm")
+	if got := ColorProfile(); got != ANSI16 {
+		t.Fatalf("Expected 16 colors on xterm, got %s", got)
+	}
+	// the style is built before the profile is known and only resolved when written
+	sty := Styled(Color256(196))
+	var out bytes.Buffer
+	io.WriteString(&styleWriter{w: &out, depth: ColorProfile(), attrs: allAttributes}, sty.ApplyTo("x"))
+	if got := out.String(); got != "\x1b[91mx\x1b[0m" {
+		t.Errorf("Expected a 256 color style to use the nearest basic color, got %q", got)
+	}
+}
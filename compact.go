@@ -0,0 +1,81 @@
+package clt
+
+import (
+	"fmt"
+	"sync"
+	"unicode/utf8"
+)
+
+// compactLayout is the terminal width below which indicators switch to their compact
+// layout
+var compactLayout = struct {
+	sync.RWMutex
+	width int
+}{width: 40}
+
+// SetCompactWidth sets the terminal width, in columns, below which progress
+// indicators use a compact layout instead of wrapping, such as on phone SSH clients
+// and embedded consoles.  Bars show only the percentage, results are shown as a
+// single character, and elapsed time, resource usage and statuses are left out.
+// Defaults to 40 columns.  Zero turns the compact layout off.
+func SetCompactWidth(cols int) {
+	compactLayout.Lock()
+	defer compactLayout.Unlock()
+	compactLayout.width = cols
+}
+
+// compact returns true if the indicator is drawn with the compact layout.  Must be
+// called with the mutex held.
+func (p *Progress) compact() bool {
+	compactLayout.RLock()
+	threshold := compactLayout.width
+	compactLayout.RUnlock()
+	width := p.width()
+	return p.style != loading && !p.multiline() && width > 0 && width < threshold
+}
+
+// compactLine returns the compact layout of s, such as Copying 45% or Copying ✓.
+// Must be called with the mutex held.
+func (p *Progress) compactLine(s frameState, frame int) string {
+	var status string
+	sty := &Style{}
+	switch {
+	case s.state == finished:
+		status, sty = s.compactOutcome()
+	case p.style == bar && s.total > 0:
+		status = fmt.Sprintf("%d%%", scale(s.current, s.total, 100))
+	case p.style == bar:
+		status = fmt.Sprintf("%.0f%%", 100.0*s.pct)
+	default:
+		status = spinLookup(frame, p.spinsteps)
+		if p.SpinnerStyle != nil {
+			sty = p.SpinnerStyle
+		}
+	}
+	if p.style == bar {
+		return fmt.Sprintf("%s %s", p.stylePrompt(p.fitPrompt(s.prompt, displayWidth(status)+1)), sty.ApplyTo(status))
+	}
+	tally := ""
+	if s.counting {
+		tally = " " + Count(s.current)
+	}
+	return fmt.Sprintf("%s[%s]%s", p.stylePrompt(p.fitPrompt(s.prompt, displayWidth(status)+2+len(tally))), sty.ApplyTo(status), tally)
+}
+
+// compactOutcome returns the result of a finished indicator as a single character
+// and its style: ✓ for success, ✗ for failure, and the first letter of the status set
+// with Finish
+func (s frameState) compactOutcome() (string, *Style) {
+	switch s.result {
+	case success:
+		return "✓", CurrentTheme().Success
+	case custom:
+		msg, sty := s.outcome()
+		r, _ := utf8.DecodeRuneInString(msg)
+		if r == utf8.RuneError {
+			r = '•'
+		}
+		return string(r), sty
+	}
+	return "✗", CurrentTheme().Error
+}
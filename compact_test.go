@@ -0,0 +1,45 @@
+package clt
+
+import (
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCompactLayout(t *testing.T) {
+	phone := Capabilities{Name: "phone", ANSI: true, Unicode: true, Width: 30}
+
+	got := RenderWith(phone, func(w io.Writer) {
+		p := NewProgressBar("Copying", WithProgressOutput(w), WithElapsedTime())
+		p.Start()
+		p.Update(0.45)
+		p.Success()
+	})
+	if !strings.Contains(got, "\x1b[2KCopying 45%") || !strings.HasSuffix(got, "\x1b[?25h\r\x1b[2KCopying \x1b[32m✓\x1b[39m\n") {
+		t.Errorf("Expected a percentage and a single character result, got %q", got)
+	}
+
+	got = RenderWith(phone, func(w io.Writer) {
+		p := NewProgressSpinner("Fetching a very long list of packages", WithProgressOutput(w))
+		p.Interval = time.Hour
+		p.Start()
+		p.SetStatus("mirror 3")
+		p.Finish("WARN", Styled(Yellow))
+	})
+	if strings.Contains(got, "mirror 3") || !strings.HasSuffix(got, "Fetching a very long list…[\x1b[33mW\x1b[39m]\n") {
+		t.Errorf("Expected a truncated prompt and the first letter of the status, got %q", got)
+	}
+
+	// the threshold can be turned off
+	SetCompactWidth(0)
+	defer SetCompactWidth(40)
+	got = RenderWith(phone, func(w io.Writer) {
+		p := NewProgressBar("Copying", WithProgressOutput(w))
+		p.Start()
+		p.Success()
+	})
+	if !strings.Contains(got, "Copying: [") {
+		t.Errorf("Expected the full layout, got %q", got)
+	}
+}
@@ -0,0 +1,135 @@
+package clt
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"golang.org/x/crypto/ssh/terminal"
+)
+
+// DoNotTrackEnv is the environment variable that declines telemetry without asking
+// when it is set to any value other than 0
+const DoNotTrackEnv = "DO_NOT_TRACK"
+
+// ConsentStore remembers the user's answer to a TelemetryConsent prompt so that they
+// are only asked once
+type ConsentStore interface {
+	// Load returns the saved answer.  ok is false if the user hasn't been asked yet.
+	Load() (granted bool, ok bool, err error)
+	// Save records the user's answer
+	Save(granted bool) error
+}
+
+// ConsentFile returns a ConsentStore that keeps the answer in the file at path
+func ConsentFile(path string) ConsentStore {
+	return consentFile(path)
+}
+
+type consentFile string
+
+func (f consentFile) Load() (bool, bool, error) {
+	data, err := os.ReadFile(string(f))
+	switch {
+	case os.IsNotExist(err):
+		return false, false, nil
+	case err != nil:
+		return false, false, err
+	}
+	answer := strings.TrimSpace(string(data))
+	switch {
+	case IsYes(answer):
+		return true, true, nil
+	case IsNo(answer):
+		return false, true, nil
+	}
+	// an unrecognized file is treated as never having asked
+	return false, false, nil
+}
+
+func (f consentFile) Save(granted bool) error {
+	answer := "no"
+	if granted {
+		answer = "yes"
+	}
+	return os.WriteFile(string(f), []byte(answer+"\n"), 0644)
+}
+
+// TelemetryConsent asks the user whether a tool may collect usage data.  It explains
+// what is collected in a box, defaults to no, and remembers the answer in Store.
+type TelemetryConsent struct {
+	// Tool is the name of the program asking for consent
+	Tool string
+	// Collected describes each kind of data that is sent
+	Collected []string
+	// URL links to the privacy policy or documentation about the data
+	URL string
+	// Revoke is the command that changes the answer later, e.g. "mytool telemetry off"
+	Revoke string
+	// Store remembers the answer
+	Store ConsentStore
+
+	input  io.Reader
+	output io.Writer
+}
+
+// NewTelemetryConsent returns a consent prompt for tool that saves the answer in store
+func NewTelemetryConsent(tool string, store ConsentStore, collected ...string) *TelemetryConsent {
+	return &TelemetryConsent{
+		Tool:      tool,
+		Collected: collected,
+		Store:     store,
+		input:     os.Stdin,
+		output:    os.Stdout,
+	}
+}
+
+// Ask returns true if the user agreed to share usage data.  A saved answer is returned
+// without asking again.  Consent is declined without asking when DoNotTrackEnv is set,
+// and when input isn't a terminal, in which case nothing is saved so the user is asked
+// the next time the tool runs interactively.
+func (c *TelemetryConsent) Ask() (bool, error) {
+	if dnt := os.Getenv(DoNotTrackEnv); len(dnt) > 0 && dnt != "0" {
+		return false, nil
+	}
+	if c.Store != nil {
+		granted, ok, err := c.Store.Load()
+		if err != nil {
+			return false, err
+		}
+		if ok {
+			return granted, nil
+		}
+	}
+	if f, ok := c.input.(*os.File); ok && !terminal.IsTerminal(int(f.Fd())) {
+		return false, nil
+	}
+
+	fmt.Fprint(c.output, box(c.explanation(), CurrentTheme().Info, Styled(Bold)))
+	granted := confirmPrompt(c.input, c.output, "Share anonymous usage data?", false)
+	fmt.Fprintln(c.output)
+	if c.Store == nil {
+		return granted, nil
+	}
+	return granted, c.Store.Save(granted)
+}
+
+// explanation is the text shown in the consent box
+func (c *TelemetryConsent) explanation() []string {
+	lines := []string{fmt.Sprintf("%s would like to collect anonymous usage data to help improve it.", c.Tool)}
+	if len(c.Collected) > 0 {
+		lines = append(lines, "", "This includes:")
+		for _, item := range c.Collected {
+			lines = append(lines, "  • "+item)
+		}
+	}
+	lines = append(lines, "", "Nothing is sent unless you agree.")
+	if len(c.URL) > 0 {
+		lines = append(lines, "Learn more: "+c.URL)
+	}
+	if len(c.Revoke) > 0 {
+		lines = append(lines, "Change your answer at any time with: "+c.Revoke)
+	}
+	return lines
+}
@@ -0,0 +1,59 @@
+package clt
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestTelemetryConsent(t *testing.T) {
+	t.Setenv(DoNotTrackEnv, "")
+	store := ConsentFile(filepath.Join(t.TempDir(), "telemetry"))
+	out := bytes.NewBuffer(nil)
+
+	c := NewTelemetryConsent("mytool", store, "Commands you run", "Error messages")
+	c.URL = "https://example.com/privacy"
+	c.input, c.output = strings.NewReader("y\n"), out
+	granted, err := c.Ask()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !granted {
+		t.Errorf("Expected consent to be granted")
+	}
+	for _, want := range []string{"mytool would like to collect", "• Error messages", "https://example.com/privacy", "[y/N]"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("Expected prompt to contain %q, got %q", want, out.String())
+		}
+	}
+
+	// the saved answer is used without asking again
+	out.Reset()
+	c.input = strings.NewReader("n\n")
+	if granted, _ := c.Ask(); !granted || out.Len() > 0 {
+		t.Errorf("Expected the saved answer without a prompt, got %v and %q", granted, out.String())
+	}
+}
+
+func TestTelemetryConsentDefaultsToNo(t *testing.T) {
+	t.Setenv(DoNotTrackEnv, "")
+	store := ConsentFile(filepath.Join(t.TempDir(), "telemetry"))
+
+	c := NewTelemetryConsent("mytool", store)
+	c.input, c.output = strings.NewReader("\n"), bytes.NewBuffer(nil)
+	if granted, _ := c.Ask(); granted {
+		t.Errorf("Expected an empty answer to decline")
+	}
+	if granted, ok, _ := store.Load(); granted || !ok {
+		t.Errorf("Expected the declined answer to be saved, got %v %v", granted, ok)
+	}
+
+	t.Setenv(DoNotTrackEnv, "1")
+	dnt := NewTelemetryConsent("mytool", ConsentFile(filepath.Join(t.TempDir(), "telemetry")))
+	out := bytes.NewBuffer(nil)
+	dnt.input, dnt.output = strings.NewReader("y\n"), out
+	if granted, _ := dnt.Ask(); granted || out.Len() > 0 {
+		t.Errorf("Expected %s to decline without asking", DoNotTrackEnv)
+	}
+}
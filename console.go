@@ -0,0 +1,199 @@
+package clt
+
+import (
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"golang.org/x/crypto/ssh/terminal"
+)
+
+// console records whether the terminal interprets ANSI escape sequences.  It is only
+// false on Windows consoles that predate virtual terminal processing.
+var console = struct {
+	sync.RWMutex
+	ansi bool
+}{ansi: true}
+
+// consoleOutput returns w, or a writer that strips ANSI escape sequences if w is a
+// console that can't interpret them, setting the colors of the console instead.
+// Lines are still redrawn with \r, so progress indicators degrade to plain
+// re-printed lines instead of showing escape codes.
+// Other terminals get a writer that only sends the changes between styles and
+// replaces colors they can't show, even in text that wasn't styled by clt.
+func consoleOutput(w io.Writer) io.Writer {
+	console.RLock()
+	ansi := console.ansi
+	console.RUnlock()
+	f, ok := w.(*os.File)
+	if !ok || !terminal.IsTerminal(int(f.Fd())) {
+		return w
+	}
+	if ansi {
+		return &styleWriter{w: w, depth: ColorProfile(), attrs: terminalAttributes()}
+	}
+	return legacyConsole(f)
+}
+
+// plainWriter removes ANSI CSI and OSC escape sequences from everything written
+// through it.  Sequences split across writes are handled.
+type plainWriter struct {
+	w     io.Writer
+	state int
+	// style, if set, is called with the styling after each SGR sequence, once the
+	// text before it has been written
+	style  func(s sgrState)
+	sgr    sgrState
+	params []byte
+}
+
+// plainWriter parser states
+const (
+	plainText int = iota
+	plainEscape
+	plainCSI
+	plainOSC
+	plainOSCEscape
+)
+
+func (p *plainWriter) Write(b []byte) (int, error) {
+	out := make([]byte, 0, len(b))
+	for _, c := range b {
+		switch p.state {
+		case plainText:
+			switch c {
+			case 0x1b:
+				p.state = plainEscape
+			default:
+				out = append(out, c)
+			}
+		case plainEscape:
+			switch c {
+			case '[':
+				p.state = plainCSI
+				p.params = p.params[:0]
+			case ']':
+				p.state = plainOSC
+			default:
+				p.state = plainText
+			}
+		case plainCSI:
+			// parameters and intermediates end at a final byte in 0x40-0x7e
+			if c < 0x40 || c > 0x7e {
+				p.params = append(p.params, c)
+				continue
+			}
+			p.state = plainText
+			if c == 'm' && p.style != nil {
+				if _, err := p.w.Write(out); err != nil {
+					return 0, err
+				}
+				out = out[:0]
+				p.sgr.apply(string(p.params))
+				p.style(p.sgr)
+			}
+		case plainOSC:
+			switch c {
+			case 0x07:
+				p.state = plainText
+			case 0x1b:
+				p.state = plainOSCEscape
+			}
+		case plainOSCEscape:
+			p.state = plainText
+			if c != '\\' {
+				p.state = plainOSC
+			}
+		}
+	}
+	if _, err := p.w.Write(out); err != nil {
+		return 0, err
+	}
+	return len(b), nil
+}
+
+// Windows console character attributes
+const (
+	consoleBlue      = 0x1
+	consoleGreen     = 0x2
+	consoleRed       = 0x4
+	consoleIntensity = 0x8
+	// consoleForeground and consoleBackground are the bits of each color
+	consoleForeground = 0x0f
+	consoleBackground = 0xf0
+	consoleUnderscore = 0x8000
+)
+
+// consoleAttributes returns the attributes of a Windows console that show the styling
+// s, starting from the attributes def that the console had before any styles.  Colors
+// beyond the basic 16 are matched to the nearest of them.
+func consoleAttributes(s sgrState, def uint16) uint16 {
+	fg, bg := def&consoleForeground, (def&consoleBackground)>>4
+	if c, ok := consoleColor(s.fg, 30, 90); ok {
+		fg = c
+	}
+	if c, ok := consoleColor(s.bg, 40, 100); ok {
+		bg = c
+	}
+	if s.bold {
+		fg |= consoleIntensity
+	}
+	if s.reverse {
+		fg, bg = bg, fg
+	}
+	attr := def&^(consoleForeground|consoleBackground|consoleUnderscore) | fg | bg<<4
+	if s.underline {
+		attr |= consoleUnderscore
+	}
+	return attr
+}
+
+// consoleColor returns the console color for the SGR parameters of a color, where
+// basic and bright are the first parameters of the basic and bright colors.  ok is
+// false for the default color.
+func consoleColor(params string, basic int, bright int) (color uint16, ok bool) {
+	if len(params) == 0 {
+		return 0, false
+	}
+	p := strings.Split(params, ";")
+	n, _ := strconv.Atoi(p[0])
+	var index int
+	switch {
+	case n >= basic && n < basic+8:
+		index = n - basic
+	case n >= bright && n < bright+8:
+		index = n - bright + 8
+	case len(p) == 3:
+		// 38;5;n from the 256 color palette
+		i, _ := strconv.Atoi(p[2])
+		c := Color256(uint8(i))
+		index = currentQuantizer()(c.rgb, palette16)
+	case len(p) == 5:
+		// 38;2;r;g;b
+		var rgb [3]uint8
+		for i := range rgb {
+			v, _ := strconv.Atoi(p[i+2])
+			rgb[i] = uint8(v)
+		}
+		index = currentQuantizer()(RGBColor{rgb[0], rgb[1], rgb[2]}, palette16)
+	default:
+		return 0, false
+	}
+	// ANSI colors mix red, green and blue in the opposite order to the console
+	var c uint16
+	if index&1 != 0 {
+		c |= consoleRed
+	}
+	if index&2 != 0 {
+		c |= consoleGreen
+	}
+	if index&4 != 0 {
+		c |= consoleBlue
+	}
+	if index >= 8 {
+		c |= consoleIntensity
+	}
+	return c, true
+}
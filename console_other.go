@@ -0,0 +1,14 @@
+//go:build !windows
+
+package clt
+
+import (
+	"io"
+	"os"
+)
+
+// legacyConsole returns a writer for a console that can't interpret escape sequences.
+// Only Windows has such consoles, so elsewhere the sequences are just removed.
+func legacyConsole(f *os.File) io.Writer {
+	return &plainWriter{w: f}
+}
@@ -0,0 +1,68 @@
+package clt
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestPlainWriter(t *testing.T) {
+	var out bytes.Buffer
+	w := &plainWriter{w: &out}
+	// sequences split across writes are still removed
+	for _, s := range []string{"\x1b[?25l\rCopying [", "\x1b[3", "2mOK\x1b[39m]\x1b]2;title\x07", "\x1b]2;x\x1b\\\n"} {
+		if n, err := w.Write([]byte(s)); err != nil || n != len(s) {
+			t.Fatalf("Write returned %d, %v", n, err)
+		}
+	}
+	if want := "\rCopying [OK]\n"; out.String() != want {
+		t.Errorf("Expected %q, got %q", want, out.String())
+	}
+}
+
+func TestPlainWriterStyle(t *testing.T) {
+	var out bytes.Buffer
+	var styles []string
+	w := &plainWriter{w: &out, style: func(s sgrState) {
+		styles = append(styles, out.String()+"|"+s.fg)
+	}}
+	for _, s := range []string{"a\x1b[3", "1mb\x1b[39mc"} {
+		w.Write([]byte(s))
+	}
+	// each style is set once the text before it has been written
+	want := []string{"a|31", "ab|"}
+	if len(styles) != len(want) || styles[0] != want[0] || styles[1] != want[1] {
+		t.Errorf("Expected styles %q, got %q", want, styles)
+	}
+	if out.String() != "abc" {
+		t.Errorf("Expected the sequences to be removed, got %q", out.String())
+	}
+}
+
+func TestConsoleAttributes(t *testing.T) {
+	// light gray on black
+	const def = 0x07
+	tt := []struct {
+		Name string
+		SGR  string
+		Want uint16
+	}{
+		{Name: "default", SGR: "0", Want: def},
+		{Name: "red", SGR: "31", Want: consoleRed},
+		{Name: "bright blue", SGR: "94", Want: consoleBlue | consoleIntensity},
+		{Name: "bold yellow", SGR: "1;33", Want: consoleRed | consoleGreen | consoleIntensity},
+		{Name: "background", SGR: "42", Want: def | consoleGreen<<4},
+		{Name: "reverse", SGR: "7;36", Want: consoleGreen<<4 | consoleBlue<<4},
+		{Name: "underline", SGR: "4", Want: def | consoleUnderscore},
+		{Name: "256", SGR: "38;5;196", Want: consoleRed | consoleIntensity},
+		{Name: "rgb", SGR: "38;2;0;205;0", Want: consoleGreen},
+	}
+	for _, tc := range tt {
+		t.Run(tc.Name, func(t *testing.T) {
+			var s sgrState
+			s.apply(tc.SGR)
+			if got := consoleAttributes(s, def); got != tc.Want {
+				t.Errorf("Expected %#x, got %#x", tc.Want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,66 @@
+//go:build windows
+
+package clt
+
+import (
+	"io"
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+// enableVirtualTerminalProcessing is the console mode flag that makes Windows 10 and
+// later interpret ANSI escape sequences
+const enableVirtualTerminalProcessing = 0x0004
+
+var (
+	setConsoleMode             = syscall.NewLazyDLL("kernel32.dll").NewProc("SetConsoleMode")
+	setConsoleTextAttribute    = syscall.NewLazyDLL("kernel32.dll").NewProc("SetConsoleTextAttribute")
+	getConsoleScreenBufferInfo = syscall.NewLazyDLL("kernel32.dll").NewProc("GetConsoleScreenBufferInfo")
+)
+
+// consoleScreenBufferInfo is CONSOLE_SCREEN_BUFFER_INFO, which describes the size,
+// cursor and attributes of a console
+type consoleScreenBufferInfo struct {
+	size              [2]int16
+	cursorPosition    [2]int16
+	attributes        uint16
+	window            [4]int16
+	maximumWindowSize [2]int16
+}
+
+func init() {
+	ansi := true
+	for _, f := range []*os.File{os.Stdout, os.Stderr} {
+		h := syscall.Handle(f.Fd())
+		var mode uint32
+		if err := syscall.GetConsoleMode(h, &mode); err != nil {
+			// not a console, e.g. redirected to a file
+			continue
+		}
+		if mode&enableVirtualTerminalProcessing != 0 {
+			continue
+		}
+		if r, _, _ := setConsoleMode.Call(uintptr(h), uintptr(mode|enableVirtualTerminalProcessing)); r == 0 {
+			ansi = false
+		}
+	}
+	console.Lock()
+	console.ansi = ansi
+	console.Unlock()
+}
+
+// legacyConsole returns a writer for a console that can't interpret escape sequences.
+// They are removed, and styles are shown by setting the text attributes of the
+// console instead.
+func legacyConsole(f *os.File) io.Writer {
+	h := f.Fd()
+	var info consoleScreenBufferInfo
+	if r, _, _ := getConsoleScreenBufferInfo.Call(h, uintptr(unsafe.Pointer(&info))); r == 0 {
+		return &plainWriter{w: f}
+	}
+	def := info.attributes
+	return &plainWriter{w: f, style: func(s sgrState) {
+		setConsoleTextAttribute.Call(h, uintptr(consoleAttributes(s, def)))
+	}}
+}
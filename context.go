@@ -0,0 +1,66 @@
+package clt
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+var contextLine = struct {
+	sync.RWMutex
+	env  string
+	text string
+}{}
+
+// SetContextLine sets a line of global context, such as the current profile, environment
+// or region, that is shown above progress indicators and interactive prompts so that it
+// stays visible across all widget activity.  The line is styled by env as a reversed badge
+// of the current theme: production environments use the error style, staging the warning
+// style, and anything else the info style.  It can be called at any time and running
+// indicators pick up the change on their next render.
+func SetContextLine(env string, format string, args ...interface{}) {
+	contextLine.Lock()
+	defer contextLine.Unlock()
+	contextLine.env = env
+	contextLine.text = fmt.Sprintf(format, args...)
+}
+
+// ClearContextLine removes the context line set with SetContextLine
+func ClearContextLine() {
+	contextLine.Lock()
+	defer contextLine.Unlock()
+	contextLine.env = ""
+	contextLine.text = ""
+}
+
+// ContextLine returns the styled context line, or an empty string if it hasn't been set
+func ContextLine() string {
+	contextLine.RLock()
+	defer contextLine.RUnlock()
+	if len(contextLine.env) == 0 && len(contextLine.text) == 0 {
+		return ""
+	}
+	var line string
+	switch {
+	case len(contextLine.env) == 0:
+		line = contextLine.text
+	case len(contextLine.text) == 0:
+		line = contextLine.env
+	default:
+		line = fmt.Sprintf("%s | %s", contextLine.env, contextLine.text)
+	}
+	return contextStyle(contextLine.env).ApplyTo(fmt.Sprintf(" %s ", line))
+}
+
+// contextStyle returns the style for the context line of an environment, taken from
+// the current theme
+func contextStyle(env string) *Style {
+	theme := CurrentTheme()
+	switch strings.ToLower(env) {
+	case "prod", "production", "prd", "live":
+		return emphasized(theme.Error, Reverse, Bold)
+	case "stage", "staging", "preprod":
+		return emphasized(theme.Warning, Reverse)
+	}
+	return emphasized(theme.Info, Reverse)
+}
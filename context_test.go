@@ -0,0 +1,53 @@
+package clt
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestContextLine(t *testing.T) {
+	defer ClearContextLine()
+	if got := ContextLine(); got != "" {
+		t.Errorf("Expected no context line, got %q", got)
+	}
+	SetContextLine("production", "profile=%s", "admin")
+	want := emphasized(Styled(Red), Reverse, Bold).ApplyTo(" production | profile=admin ")
+	if got := ContextLine(); got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+	SetContextLine("dev", "")
+	want = emphasized(Styled(Cyan), Reverse).ApplyTo(" dev ")
+	if got := ContextLine(); got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+}
+
+func TestContextLineTheme(t *testing.T) {
+	defer ClearContextLine()
+	defer SetTheme(DefaultTheme)
+	SetTheme(Theme{Warning: Styled(Magenta)})
+	SetContextLine("staging", "")
+	want := emphasized(Styled(Magenta), Reverse).ApplyTo(" staging ")
+	if got := ContextLine(); got != want {
+		t.Errorf("Expected the context line to use the theme warning style %q, got %q", want, got)
+	}
+}
+
+func TestContextLineAboveProgress(t *testing.T) {
+	defer ClearContextLine()
+	SetContextLine("staging", "us-east-1")
+	out := bytes.NewBuffer(nil)
+
+	p := NewProgressBar("Deploying")
+	p.output = out
+	p.Start()
+	p.Update(0.5)
+	p.Success()
+
+	frames := strings.Split(out.String(), "\x1b[?25l")
+	last := frames[len(frames)-1]
+	if !strings.HasPrefix(last, "\x1b[1A\r\x1b[2K"+ContextLine()+"\n\r\x1b[2KDeploying") {
+		t.Errorf("Expected context line above the bar, got %q", last)
+	}
+}
@@ -0,0 +1,109 @@
+package clt
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+// Countdown shows when scheduled work will next run, in a given time zone and
+// relative to now, e.g. Next run at 02:00 CET (in 3h 12m)
+type Countdown struct {
+	// Label is shown before the time.  Defaults to "Next run".
+	Label string
+	At    time.Time
+	// Location is the time zone the time is shown in.  Defaults to the local zone.
+	Location *time.Location
+
+	output io.Writer
+	now    func() time.Time
+}
+
+// NewCountdown returns a countdown to at, shown in the time zone loc
+func NewCountdown(at time.Time, loc *time.Location) *Countdown {
+	return &Countdown{
+		Label:    "Next run",
+		At:       at,
+		Location: loc,
+		output:   os.Stdout,
+		now:      time.Now,
+	}
+}
+
+// String renders the countdown in the format of the language set with SetLanguage.
+// The date is included when the run is more than a day away.
+func (c *Countdown) String() string {
+	loc := c.Location
+	if loc == nil {
+		loc = time.Local
+	}
+	label := c.Label
+	if len(label) == 0 {
+		label = "Next run"
+	}
+	now, at := c.now().In(loc), c.At.In(loc)
+
+	d := at.Sub(now)
+	f := currentFormat()
+	layout := f.timeLayout
+	if d >= 24*time.Hour {
+		layout = f.dateLayout
+	}
+	if d <= 0 {
+		return fmt.Sprintf("%s at %s (now)", label, at.Format(layout))
+	}
+	return fmt.Sprintf("%s at %s (in %s)", label, at.Format(layout), shortDuration(d))
+}
+
+// Run shows the countdown, updating the relative time every second, until the time
+// is reached or ctx is canceled, in which case ctx.Err() is returned.  When the
+// output isn't a terminal, the countdown is written once.
+func (c *Countdown) Run(ctx context.Context) error {
+	live := isTerminal(c.output)
+	if !live {
+		fmt.Fprintf(c.output, "%s\n", c)
+	}
+	for {
+		if live {
+			fmt.Fprintf(c.output, "\r\x1b[2K%s", c)
+		}
+		wait := c.At.Sub(c.now())
+		if wait <= 0 {
+			break
+		}
+		if wait > time.Second {
+			wait = time.Second
+		}
+		select {
+		case <-ctx.Done():
+			if live {
+				fmt.Fprintln(c.output)
+			}
+			return ctx.Err()
+		case <-time.After(wait):
+		}
+	}
+	if live {
+		fmt.Fprintln(c.output)
+	}
+	return nil
+}
+
+// shortDuration formats d in its two largest units, e.g. 3h 12m, 12m 5s or 45s.  It
+// rounds up to the next second so that a countdown never shows 0s before it ends.
+func shortDuration(d time.Duration) string {
+	secs := int64((d + time.Second - 1) / time.Second)
+	days, hours, mins := secs/86400, secs/3600%24, secs/60%60
+	secs %= 60
+	switch {
+	case days > 0:
+		return fmt.Sprintf("%dd %dh", days, hours)
+	case hours > 0:
+		return fmt.Sprintf("%dh %dm", hours, mins)
+	case mins > 0:
+		return fmt.Sprintf("%dm %ds", mins, secs)
+	}
+	return fmt.Sprintf("%ds", secs)
+}
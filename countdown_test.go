@@ -0,0 +1,50 @@
+package clt
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+)
+
+func TestCountdown(t *testing.T) {
+	cet := time.FixedZone("CET", 3600)
+	now := time.Date(2026, 3, 2, 21, 48, 0, 0, time.UTC)
+
+	tt := []struct {
+		at   time.Time
+		want string
+	}{
+		{time.Date(2026, 3, 3, 1, 0, 0, 0, time.UTC), "Next run at 02:00 CET (in 3h 12m)"},
+		{time.Date(2026, 3, 2, 21, 50, 30, 0, time.UTC), "Next run at 22:50 CET (in 2m 30s)"},
+		{time.Date(2026, 3, 5, 1, 0, 0, 0, time.UTC), "Next run at Thu Mar 5 02:00 CET (in 2d 3h)"},
+		{now.Add(-time.Minute), "Next run at 22:47 CET (now)"},
+	}
+	for _, tc := range tt {
+		c := NewCountdown(tc.at, cet)
+		c.now = func() time.Time { return now }
+		if got := c.String(); got != tc.want {
+			t.Errorf("Expected %q, got %q", tc.want, got)
+		}
+	}
+}
+
+func TestCountdownRun(t *testing.T) {
+	out := bytes.NewBuffer(nil)
+	c := NewCountdown(time.Now().Add(50*time.Millisecond), time.UTC)
+	c.output = out
+	if err := c.Run(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if time.Now().Before(c.At) {
+		t.Errorf("Expected Run to wait until the scheduled time")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	c = NewCountdown(time.Now().Add(time.Hour), time.UTC)
+	c.output = out
+	if err := c.Run(ctx); err != context.Canceled {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+}
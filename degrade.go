@@ -0,0 +1,131 @@
+package clt
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+// Capabilities describes a terminal to simulate with RenderWith, so that the way a
+// UI degrades can be checked without owning every kind of terminal
+type Capabilities struct {
+	// Name identifies the capabilities, such as for naming a snapshot
+	Name string
+	// ANSI is false for terminals that print escape sequences instead of interpreting
+	// them, in which case styles and cursor movement are removed
+	ANSI bool
+	// Unicode is false for terminals that can only show ASCII.  Other characters are
+	// replaced by ASCII lookalikes padded to the same width, or by ?.
+	Unicode bool
+	// Width is the number of columns of the terminal
+	Width int
+}
+
+// Capability profiles for RenderAll
+var (
+	// DumbTerminal prints escape sequences literally, like TERM=dumb or a CI log
+	DumbTerminal = Capabilities{Name: "dumb", Unicode: true, Width: 80}
+	// ColorTerminal shows the 16 basic colors that clt uses for every style
+	ColorTerminal = Capabilities{Name: "16-color", ANSI: true, Unicode: true, Width: 80}
+	// ASCIITerminal interprets escape sequences but can only show ASCII
+	ASCIITerminal = Capabilities{Name: "no-unicode", ANSI: true, Width: 80}
+	// NarrowTerminal is a terminal only 40 columns wide
+	NarrowTerminal = Capabilities{Name: "narrow", ANSI: true, Unicode: true, Width: 40}
+)
+
+// CapabilityProfiles are the terminals simulated by RenderAll
+var CapabilityProfiles = []Capabilities{DumbTerminal, ColorTerminal, ASCIITerminal, NarrowTerminal}
+
+// RenderWith returns what render writes as it would appear on a terminal with the
+// capabilities c.  Widgets that size themselves to the terminal, such as progress
+// indicators given w with WithProgressOutput, use c.Width.
+func RenderWith(c Capabilities, render func(w io.Writer)) string {
+	var buf bytes.Buffer
+	w := &capabilityWriter{caps: c, w: &buf}
+	if !c.ANSI {
+		w.w = &plainWriter{w: &buf}
+	}
+	render(w)
+	w.flush()
+	return buf.String()
+}
+
+// RenderAll renders under each of CapabilityProfiles and returns the output by name
+func RenderAll(render func(w io.Writer)) map[string]string {
+	out := make(map[string]string, len(CapabilityProfiles))
+	for _, c := range CapabilityProfiles {
+		out[c.Name] = RenderWith(c, render)
+	}
+	return out
+}
+
+// capabilityWriter applies Capabilities to everything written through it and
+// reports their width to widgets that size themselves to the terminal
+type capabilityWriter struct {
+	caps Capabilities
+	w    io.Writer
+	// partial is the start of a character split across writes
+	partial []byte
+}
+
+func (c *capabilityWriter) Write(b []byte) (int, error) {
+	if c.caps.Unicode {
+		return c.w.Write(b)
+	}
+	data := append(c.partial, b...)
+	c.partial = nil
+	var out strings.Builder
+	for len(data) > 0 {
+		if !utf8.FullRune(data) {
+			c.partial = append([]byte(nil), data...)
+			break
+		}
+		r, size := utf8.DecodeRune(data)
+		data = data[size:]
+		out.WriteString(asciiFallback(r))
+	}
+	if _, err := io.WriteString(c.w, out.String()); err != nil {
+		return 0, err
+	}
+	return len(b), nil
+}
+
+// flush writes a character left incomplete at the end of the output
+func (c *capabilityWriter) flush() {
+	if len(c.partial) > 0 {
+		c.w.Write([]byte("?"))
+		c.partial = nil
+	}
+}
+
+// asciiLookalikes replace the glyphs that widgets draw
+var asciiLookalikes = map[rune]string{
+	'✓': "v", '✗': "x", '•': "*", '→': ">", '▶': ">", '…': ".",
+	'─': "-", '│': "|", '╭': "+", '╮': "+", '╰': "+", '╯': "+", '├': "|", '└': "`",
+	'█': "#", '▉': "#", '▊': "#", '▋': "=", '▌': "=", '▍': "-", '▎': "-", '▏': "-",
+	'←': "<", '↑': "^", '↓': "v", '↖': "\\", '↗': "/", '↘': "\\", '↙': "/",
+}
+
+// asciiFallback returns r, or an ASCII lookalike padded to the width of r
+func asciiFallback(r rune) string {
+	switch {
+	case r < utf8.RuneSelf:
+		return string(r)
+	case runeWidth(r) == 0:
+		return ""
+	}
+	s, ok := asciiLookalikes[r]
+	switch {
+	case ok:
+	case r >= 0x2800 && r <= 0x28ff:
+		// braille spinners
+		s = "*"
+	default:
+		s = "?"
+	}
+	if w := runeWidth(r); w > len(s) {
+		s += strings.Repeat(" ", w-len(s))
+	}
+	return s
+}
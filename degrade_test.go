@@ -0,0 +1,69 @@
+package clt
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestRenderAll(t *testing.T) {
+	out := RenderAll(func(w io.Writer) {
+		p := NewProgressBar("Syncing café", WithProgressOutput(w), WithPartialBlocks())
+		p.Start()
+		p.Update(0.55)
+		p.Success()
+		fmt.Fprintln(w, Styled(Green).ApplyTo("✓ done"))
+	})
+	if len(out) != len(CapabilityProfiles) {
+		t.Fatalf("Expected output for every profile, got %d", len(out))
+	}
+
+	if strings.Contains(out["dumb"], "\x1b") {
+		t.Errorf("Expected no escape sequences on a dumb terminal, got %q", out["dumb"])
+	}
+	if !strings.Contains(out["16-color"], "\x1b[32m✓ done\x1b[39m") {
+		t.Errorf("Expected styles on a color terminal, got %q", out["16-color"])
+	}
+	ascii := out["no-unicode"]
+	for _, r := range ascii {
+		if r >= 0x80 {
+			t.Errorf("Expected only ASCII, got %q in %q", r, ascii)
+			break
+		}
+	}
+	if !strings.Contains(ascii, "Syncing caf?: [") || !strings.Contains(ascii, "\x1b[32mv done") {
+		t.Errorf("Expected ASCII lookalikes, got %q", ascii)
+	}
+
+	// the bar is sized to the terminal, leaving the last column empty
+	for _, name := range []string{"16-color", "narrow"} {
+		lines := strings.Split(strings.TrimSuffix(out[name], "\n"), "\r")
+		last := strings.SplitN(lines[len(lines)-1], "\n", 2)[0]
+		want := 79
+		if name == "narrow" {
+			want = 39
+		}
+		if got := displayWidth(stripANSI(last)); got != want {
+			t.Errorf("%s: expected the bar to be %d columns, got %d in %q", name, want, got, last)
+		}
+	}
+}
+
+func TestASCIIFallbackSplitWrites(t *testing.T) {
+	got := RenderWith(ASCIITerminal, func(w io.Writer) {
+		b := []byte("→ 日本")
+		w.Write(b[:2])
+		w.Write(b[2:6])
+		w.Write(b[6:])
+	})
+	if got != "> ? ? " {
+		t.Errorf("Expected characters split across writes to be replaced once, got %q", got)
+	}
+}
+
+func stripANSI(s string) string {
+	var b strings.Builder
+	(&plainWriter{w: &b}).Write([]byte(s))
+	return b.String()
+}
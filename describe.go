@@ -0,0 +1,117 @@
+package clt
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// describeMinValueWidth is the narrowest that values are wrapped to beside their
+// names.  Narrower values start on the line below instead.
+const describeMinValueWidth = 20
+
+// DescribeList shows the fields of a single record as aligned name and value pairs,
+// like kubectl describe, where a table would have only one row
+type DescribeList struct {
+	blocks []describeBlock
+	writer io.Writer
+}
+
+// describeBlock is a section of a DescribeList, whose names are aligned together
+type describeBlock struct {
+	title string
+	items []describeItem
+}
+
+type describeItem struct {
+	name  string
+	value string
+}
+
+// NewDescribeList returns an empty list that writes to Stdout
+func NewDescribeList() *DescribeList {
+	return &DescribeList{blocks: []describeBlock{{}}, writer: os.Stdout}
+}
+
+// Add adds a name and value to the current section.  Values are formatted like
+// fmt.Sprint, so secrets are masked.
+func (d *DescribeList) Add(name string, value interface{}) *DescribeList {
+	b := &d.blocks[len(d.blocks)-1]
+	b.items = append(b.items, describeItem{name: name, value: fmt.Sprint(value)})
+	return d
+}
+
+// Section starts a section with a header in bold and the theme's prompt style.  The
+// names and values added after it are indented beneath the header.
+func (d *DescribeList) Section(title string) *DescribeList {
+	d.blocks = append(d.blocks, describeBlock{title: title})
+	return d
+}
+
+// SetWriter sets the output writer if not writing to Stdout
+func (d *DescribeList) SetWriter(w io.Writer) {
+	d.writer = w
+}
+
+// Show writes the list to the configured writer
+func (d *DescribeList) Show() {
+	fmt.Fprint(consoleOutput(d.writer), d.AsString())
+}
+
+// AsString returns the rendered list as a string instead of immediately writing to
+// the configured writer.  Long values are wrapped to the width of the terminal, or
+// 80 columns, with each line starting under the first.
+func (d *DescribeList) AsString() string {
+	width := terminalWidth(d.writer)
+	if width <= 0 {
+		width = 80
+	}
+	var b strings.Builder
+	for _, block := range d.blocks {
+		indent := ""
+		if len(block.title) > 0 {
+			b.WriteString(emphasized(CurrentTheme().Prompt, Bold).ApplyTo(block.title+":") + "\n")
+			indent = "  "
+		}
+		names := 0
+		for _, item := range block.items {
+			if w := VisibleWidth(item.name) + 1; w > names {
+				names = w
+			}
+		}
+		// values start two columns after the longest name and its colon
+		col := len(indent) + names + 2
+		for _, item := range block.items {
+			label := indent + item.name + ":"
+			// Wrap only breaks at \n, so \r\n line endings are normalized first
+			value := strings.Join(splitLines(item.value), "\n")
+			switch {
+			case len(value) == 0:
+				b.WriteString(label + "\n")
+			case width-col < describeMinValueWidth:
+				b.WriteString(label + "\n")
+				b.WriteString(Indent(Wrap(value, wrapWidth(width-len(indent)-2)), indent+"  ") + "\n")
+			default:
+				lines := splitLines(Wrap(value, wrapWidth(width-col)))
+				b.WriteString(label + strings.Repeat(" ", col-VisibleWidth(label)) + lines[0] + "\n")
+				for _, line := range lines[1:] {
+					if len(line) > 0 {
+						line = strings.Repeat(" ", col) + line
+					}
+					b.WriteString(line + "\n")
+				}
+			}
+		}
+	}
+	return b.String()
+}
+
+// wrapWidth returns width for Wrap, which is at least one column so that a narrow
+// writer never falls back to the width of standard output
+func wrapWidth(width int) int {
+	if width < 1 {
+		return 1
+	}
+	return width
+}
@@ -0,0 +1,83 @@
+package clt
+
+import (
+	"io"
+	"testing"
+)
+
+func TestDescribeList(t *testing.T) {
+	d := NewDescribeList().
+		Add("Name", "web-7d4b9").
+		Add("Namespace", "default").
+		Add("Restarts", 3).
+		Add("Token", Secret("hunter2")).
+		Section("Labels").
+		Add("app", "web").
+		Add("description", "serves the public site and the admin console for every region").
+		Add("empty", "").
+		Section("Events").
+		Add("Pulled", "image pulled\nstarted container")
+
+	got := RenderWith(Capabilities{Name: "narrow", ANSI: true, Unicode: true, Width: 50}, func(w io.Writer) {
+		d.SetWriter(w)
+		d.Show()
+	})
+	want := "Name:       web-7d4b9\n" +
+		"Namespace:  default\n" +
+		"Restarts:   3\n" +
+		"Token:      " + Mask + "\n" +
+		"Labels:\n" +
+		"  app:          web\n" +
+		"  description:  serves the public site and the\n" +
+		"                admin console for every region\n" +
+		"  empty:\n" +
+		"Events:\n" +
+		"  Pulled:  image pulled\n" +
+		"           started container\n"
+	if Strip(got) != want {
+		t.Errorf("Expected:\n%s\ngot:\n%s", want, Strip(got))
+	}
+}
+
+func TestDescribeListNarrow(t *testing.T) {
+	d := NewDescribeList().Add("A very long field name", "a value that wraps onto the lines below")
+	got := RenderWith(Capabilities{Name: "narrow", ANSI: true, Unicode: true, Width: 30}, func(w io.Writer) {
+		d.SetWriter(w)
+		d.Show()
+	})
+	want := "A very long field name:\n" +
+		"  a value that wraps onto the\n" +
+		"  lines below\n"
+	if Strip(got) != want {
+		t.Errorf("Expected:\n%s\ngot:\n%s", want, Strip(got))
+	}
+}
+
+func TestDescribeListLineEndings(t *testing.T) {
+	d := NewDescribeList().Add("Log", "first\r\nsecond")
+	want := "Log:  first\n      second\n"
+	if got := Strip(d.AsString()); got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+}
+
+func TestDescribeListTinyWidth(t *testing.T) {
+	d := NewDescribeList().Section("Labels").Add("app", "web")
+	got := RenderWith(Capabilities{Name: "tiny", ANSI: true, Unicode: true, Width: 3}, func(w io.Writer) {
+		d.SetWriter(w)
+		d.Show()
+	})
+	want := "Labels:\n  app:\n    w\n    e\n    b\n"
+	if Strip(got) != want {
+		t.Errorf("Expected %q, got %q", want, Strip(got))
+	}
+}
+
+func TestDescribeListTheme(t *testing.T) {
+	defer SetTheme(DefaultTheme)
+	SetTheme(Theme{Prompt: Styled(Blue)})
+	got := NewDescribeList().Section("Labels").AsString()
+	if want := emphasized(Styled(Blue), Bold).ApplyTo("Labels:") + "\n"; got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+}
@@ -0,0 +1,42 @@
+package clt
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrInsufficientSpace is returned by CheckDiskSpace when there isn't enough free space
+var ErrInsufficientSpace = errors.New("not enough free disk space")
+
+// CheckDiskSpace shows a gauge of the space required against the space free on the
+// filesystem containing path, and returns an error wrapping ErrInsufficientSpace if
+// required is more than is free.  Installers can call it before starting large
+// downloads.  The gauge is sized like a progress bar and takes the same options.
+func CheckDiskSpace(path string, required int64, opts ...ProgressOption) error {
+	free, err := diskFree(path)
+	if err != nil {
+		return err
+	}
+	p := NewProgressBar("Disk space").apply(opts)
+	fmt.Fprintf(p.output, "%s\n", p.diskGauge(free, required))
+	if required > free {
+		return fmt.Errorf("%w: %s needed on %s but only %s is free", ErrInsufficientSpace, Bytes(required), path, Bytes(free))
+	}
+	return nil
+}
+
+// diskGauge renders the share of the free space that is required, in green if it
+// fits and as a full red gauge if it doesn't
+func (p *Progress) diskGauge(free int64, required int64) string {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	detail := fmt.Sprintf(" %s needed, %s free", Bytes(required), Bytes(free))
+	prompt := p.fitPrompt(p.Prompt, barDecorations+len(detail)+minBarLength)
+	length := p.displayLength(displayWidth(prompt), barDecorations+len(detail))
+	if required > free {
+		return fmt.Sprintf("%s: [%s]%s", prompt, CurrentTheme().Error.ApplyTo(strings.Repeat("=", length)), detail)
+	}
+	n := scale(required, free, length)
+	return fmt.Sprintf("%s: [%s%s]%s", prompt, CurrentTheme().Success.ApplyTo(strings.Repeat("=", n)), strings.Repeat(" ", length-n), detail)
+}
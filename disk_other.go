@@ -0,0 +1,9 @@
+//go:build !linux && !darwin && !dragonfly && !freebsd && !windows
+
+package clt
+
+import "errors"
+
+func diskFree(path string) (int64, error) {
+	return 0, errors.New("checking free disk space is not supported on this platform")
+}
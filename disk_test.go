@@ -0,0 +1,31 @@
+package clt
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestCheckDiskSpace(t *testing.T) {
+	out := bytes.NewBuffer(nil)
+	dir := t.TempDir()
+	if err := CheckDiskSpace(dir, 1, WithProgressOutput(out)); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "1 B needed") {
+		t.Errorf("Expected the gauge to show the space needed, got %q", out.String())
+	}
+
+	err := CheckDiskSpace(dir, 1<<62, WithProgressOutput(out))
+	if !errors.Is(err, ErrInsufficientSpace) {
+		t.Errorf("Expected ErrInsufficientSpace, got %v", err)
+	}
+}
+
+func TestDiskGauge(t *testing.T) {
+	p := NewProgressBar("Disk space", WithLength(10))
+	if got, want := p.diskGauge(4000, 1000), "Disk space: [\x1b[32m==\x1b[39m        ] 1 kB needed, 4 kB free"; got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+}
@@ -0,0 +1,131 @@
+package clt
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Download streams url to the file dest while showing a progress bar with the bytes
+// transferred, the rate, and the time left.  When the server doesn't send the size, a
+// spinner with the bytes transferred is shown instead.  The file is written to a
+// temporary name next to dest and only renamed to dest once the download completes.
+func Download(ctx context.Context, url string, dest string, opts ...ProgressOption) error {
+	return DownloadWithClient(ctx, http.DefaultClient, url, dest, opts...)
+}
+
+// DownloadWithClient is like Download but makes the request with client
+func DownloadWithClient(ctx context.Context, client *http.Client, url string, dest string, opts ...ProgressOption) error {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := client.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("download %s: %s", url, resp.Status)
+	}
+
+	f, err := os.CreateTemp(filepath.Dir(dest), "."+filepath.Base(dest)+".*.part")
+	if err != nil {
+		return err
+	}
+	tmp := f.Name()
+	defer os.Remove(tmp)
+
+	prompt := "Downloading " + filepath.Base(dest)
+	var p *Progress
+	switch {
+	case resp.ContentLength > 0:
+		p = NewProgressBar("%s", prompt)
+		p.SetTotalBytes(resp.ContentLength)
+	default:
+		p = NewProgressSpinner("%s", prompt)
+	}
+	p.apply(opts)
+	counter := &downloadCounter{p: p, prompt: prompt, total: resp.ContentLength, start: time.Now()}
+	p.Start()
+
+	_, err = io.Copy(io.MultiWriter(f, counter), resp.Body)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(tmp, dest)
+	}
+	counter.done()
+	if err != nil {
+		p.Fail()
+		return err
+	}
+	return p.Success()
+}
+
+// downloadCounter updates the progress indicator as bytes are written to it,
+// redrawing at most every 100ms
+type downloadCounter struct {
+	p      *Progress
+	prompt string
+	total  int64
+	start  time.Time
+
+	mtx     sync.Mutex
+	n       int64
+	updated time.Time
+}
+
+func (c *downloadCounter) Write(b []byte) (int, error) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	c.n += int64(len(b))
+	if time.Since(c.updated) >= 100*time.Millisecond {
+		c.updated = time.Now()
+		c.update()
+	}
+	return len(b), nil
+}
+
+// update shows the count, rate and time left.  Must be called with the mutex held.
+func (c *downloadCounter) update() {
+	elapsed := time.Since(c.start)
+	rate := int64(0)
+	if elapsed > 0 {
+		rate = int64(float64(c.n) / elapsed.Seconds())
+	}
+	if c.total <= 0 {
+		c.p.UpdatePrompt(fmt.Sprintf("%s (%s, %s/s)", c.prompt, Bytes(c.n), Bytes(rate)))
+		return
+	}
+	detail := Bytes(rate) + "/s"
+	if rate > 0 && c.n < c.total {
+		left := time.Duration(float64(c.total-c.n) / float64(rate) * float64(time.Second))
+		detail += ", " + formatElapsed(left) + " left"
+	}
+	c.p.mtx.Lock()
+	c.p.detail = detail
+	c.p.mtx.Unlock()
+	c.p.UpdateCount(c.n)
+}
+
+// done shows the final count.  The rate is cleared from bars so that the finished
+// bar only shows the total.
+func (c *downloadCounter) done() {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	if c.total <= 0 {
+		c.update()
+		return
+	}
+	c.p.mtx.Lock()
+	c.p.detail = ""
+	c.p.mtx.Unlock()
+	c.p.UpdateCount(c.n)
+}
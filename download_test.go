@@ -0,0 +1,68 @@
+package clt
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestDownload(t *testing.T) {
+	data := bytes.Repeat([]byte("clt"), 100000)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+		w.Write(data)
+	}))
+	defer srv.Close()
+
+	out := bytes.NewBuffer(nil)
+	dest := filepath.Join(t.TempDir(), "data.bin")
+	if err := Download(context.Background(), srv.URL+"/data.bin", dest, WithProgressOutput(out)); err != nil {
+		t.Fatal(err)
+	}
+	got, err := os.ReadFile(dest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, data) {
+		t.Errorf("Expected %d bytes downloaded, got %d", len(data), len(got))
+	}
+	if !strings.Contains(out.String(), "(300 kB of 300 kB)") {
+		t.Errorf("Expected the byte counts on the finished bar, got %q", out.String())
+	}
+
+	missing := filepath.Join(t.TempDir(), "missing.bin")
+	if err := Download(context.Background(), srv.URL+"/missing", missing, WithProgressOutput(out)); err == nil {
+		t.Errorf("Expected an error for a missing file")
+	}
+	if _, err := os.Stat(missing); !os.IsNotExist(err) {
+		t.Errorf("Expected nothing to be written for a failed download")
+	}
+}
+
+func TestDownloadUnknownLength(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// flushing before writing everything forces a chunked response without a length
+		w.Write([]byte("part one "))
+		w.(http.Flusher).Flush()
+		w.Write([]byte("part two"))
+	}))
+	defer srv.Close()
+
+	dest := filepath.Join(t.TempDir(), "stream.txt")
+	if err := Download(context.Background(), srv.URL, dest, WithProgressOutput(bytes.NewBuffer(nil))); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := os.ReadFile(dest); string(got) != "part one part two" {
+		t.Errorf("Expected the whole stream, got %q", got)
+	}
+}
@@ -0,0 +1,176 @@
+package clt
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// EventType identifies a change in the state of a progress indicator
+type EventType string
+
+// Progress event types
+const (
+	EventStart   EventType = "start"
+	EventTick    EventType = "tick"
+	EventSuccess EventType = "success"
+	EventFail    EventType = "fail"
+	// EventFinish is sent when the indicator ends with Finish
+	EventFinish EventType = "finish"
+)
+
+// Event describes a change in the state of a progress indicator.  Events are sent
+// to every Display mirroring the indicator.
+type Event struct {
+	Type   EventType
+	Prompt string
+	// Pct is the fraction complete [0, 1.0] for bars.  It is always 0 for
+	// spinners and loading indicators.
+	Pct float64
+	// Current and Total are the counts set with SetTotal and UpdateCount
+	Current int64
+	Total   int64
+	// Message is the custom trailer set by SuccessWith, FailWith or Finish
+	Message string
+	Time    time.Time
+}
+
+// Display receives progress events so that one progress indicator can drive
+// several displays at once, such as the terminal, a machine-readable log, or the
+// title bar of the terminal window.
+type Display interface {
+	Render(e Event)
+}
+
+// DisplayFunc adapts an ordinary function to a Display
+type DisplayFunc func(e Event)
+
+// Render calls f(e)
+func (f DisplayFunc) Render(e Event) { f(e) }
+
+var globalDisplays = struct {
+	sync.Mutex
+	d []Display
+}{}
+
+// MirrorAll adds displays that receive the events of every progress indicator started
+// after the call, so enabling another output doesn't require changing call sites.
+func MirrorAll(d ...Display) {
+	globalDisplays.Lock()
+	defer globalDisplays.Unlock()
+	globalDisplays.d = append(globalDisplays.d, d...)
+}
+
+// mirrorAllDisplays returns a copy of the displays added with MirrorAll
+func mirrorAllDisplays() []Display {
+	globalDisplays.Lock()
+	defer globalDisplays.Unlock()
+	return append([]Display(nil), globalDisplays.d...)
+}
+
+// Mirror adds displays that receive every event of the progress indicator in addition
+// to the terminal rendering.  It returns the progress indicator so that it can be chained
+// with the constructor.
+func (p *Progress) Mirror(d ...Display) *Progress {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	p.displays = append(p.displays, d...)
+	return p
+}
+
+// emit sends an event built from the current state to all displays.  The bar's last
+// reported pct is used for events that don't change it.  It must be called
+// without holding the mutex so that displays are free to query the indicator.
+func (p *Progress) emit(t EventType) {
+	p.mtx.Lock()
+	pct, current := p.pct, p.current
+	if t == EventSuccess && p.style == bar {
+		pct, current = 1.0, p.total
+	}
+	e := Event{
+		Type:    t,
+		Prompt:  p.Prompt,
+		Pct:     pct,
+		Current: current,
+		Total:   p.total,
+		Message: p.message,
+		Time:    time.Now(),
+	}
+	displays := p.displays
+	var hook func(e Event)
+	switch t {
+	case EventStart:
+		hook = p.OnStart
+	case EventTick:
+		hook = p.OnTick
+	default:
+		hook = p.OnFinish
+	}
+	p.mtx.Unlock()
+	for _, d := range displays {
+		d.Render(e)
+	}
+	if hook != nil {
+		hook(e)
+	}
+}
+
+// TitleDisplay shows the prompt and percentage complete in the title bar of the
+// terminal window connected to w
+func TitleDisplay(w io.Writer) Display {
+	return DisplayFunc(func(e Event) {
+		switch e.Type {
+		case EventSuccess, EventFail:
+			fmt.Fprintf(w, "\x1b]2;%s [%s]\x07", e.Prompt, e.Type)
+		case EventFinish:
+			fmt.Fprintf(w, "\x1b]2;%s [%s]\x07", e.Prompt, e.Message)
+		default:
+			fmt.Fprintf(w, "\x1b]2;%s %.0f%%\x07", e.Prompt, 100.0*e.Pct)
+		}
+	})
+}
+
+// OutputEnv is the environment variable that selects the output of progress
+// indicators.  When it is set to json, every indicator writes JSON lines as with
+// WithJSONOutput.  The default mode of OutputFlag is set with FormatEnv instead.
+const OutputEnv = "CLT_OUTPUT"
+
+// jsonEvent is a line of JSON output
+type jsonEvent struct {
+	Event   string    `json:"event"`
+	Prompt  string    `json:"prompt"`
+	Pct     float64   `json:"pct"`
+	Current int64     `json:"current,omitempty"`
+	Total   int64     `json:"total,omitempty"`
+	Message string    `json:"message,omitempty"`
+	Time    time.Time `json:"time"`
+}
+
+// JSONDisplay writes each event to w as a line of JSON, such as
+// {"event":"tick","prompt":"Copying","pct":0.4,"time":"..."}, for tools that wrap a
+// program and want structured progress instead of terminal animation
+func JSONDisplay(w io.Writer) Display {
+	return DisplayFunc(func(e Event) {
+		writeJSONEvent(w, jsonEvent{
+			Event:   string(e.Type),
+			Prompt:  e.Prompt,
+			Pct:     e.Pct,
+			Current: e.Current,
+			Total:   e.Total,
+			Message: e.Message,
+			Time:    e.Time,
+		})
+	})
+}
+
+// writeJSONEvent writes e as a single line so that lines from concurrent indicators
+// don't interleave
+func writeJSONEvent(w io.Writer, e jsonEvent) {
+	b, err := json.Marshal(e)
+	if err != nil {
+		return
+	}
+	w.Write(append(b, '\n'))
+}
@@ -0,0 +1,100 @@
+package clt
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMirror(t *testing.T) {
+	var got []EventType
+	var pcts []float64
+	p := NewProgressBar("Mirrored")
+	p.output = bytes.NewBuffer(nil)
+	p.Mirror(DisplayFunc(func(e Event) {
+		got = append(got, e.Type)
+		pcts = append(pcts, e.Pct)
+	}))
+	p.Start()
+	p.Update(0.5)
+	p.Success()
+
+	want := []EventType{EventStart, EventTick, EventSuccess}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected events %v, got %v", want, got)
+	}
+	if !reflect.DeepEqual(pcts, []float64{0, 0.5, 1.0}) {
+		t.Errorf("Expected pcts [0 0.5 1], got %v", pcts)
+	}
+}
+
+func TestTitleDisplay(t *testing.T) {
+	var out bytes.Buffer
+	d := TitleDisplay(&out)
+	d.Render(Event{Type: EventTick, Prompt: "Copying", Pct: 0.42})
+	d.Render(Event{Type: EventSuccess, Prompt: "Copying", Pct: 1.0})
+	want := "\x1b]2;Copying 42%\x07\x1b]2;Copying [success]\x07"
+	if out.String() != want {
+		t.Errorf("Expected %q, got %q", want, out.String())
+	}
+}
+
+func TestJSONOutput(t *testing.T) {
+	out := bytes.NewBuffer(nil)
+	p := NewProgressBar("Copying", WithProgressOutput(out), WithJSONOutput())
+	p.Start()
+	p.Update(0.4)
+	p.Println("copied %d files", 3)
+	p.Success()
+
+	var events []string
+	for _, line := range strings.Split(strings.TrimSpace(out.String()), "\n") {
+		var e struct {
+			Event   string  `json:"event"`
+			Prompt  string  `json:"prompt"`
+			Pct     float64 `json:"pct"`
+			Message string  `json:"message"`
+		}
+		if err := json.Unmarshal([]byte(line), &e); err != nil {
+			t.Fatalf("Expected a line of JSON, got %q: %s", line, err)
+		}
+		if e.Prompt != "Copying" {
+			t.Errorf("Expected prompt Copying, got %q", e.Prompt)
+		}
+		events = append(events, fmt.Sprintf("%s %.1f%s", e.Event, e.Pct, e.Message))
+	}
+	want := []string{"start 0.0", "tick 0.4", "log 0.0copied 3 files", "success 1.0"}
+	if !reflect.DeepEqual(events, want) {
+		t.Errorf("Expected events %v, got %v", want, events)
+	}
+}
+
+func TestOutputEnv(t *testing.T) {
+	t.Setenv(OutputEnv, "json")
+	out := bytes.NewBuffer(nil)
+	p := NewProgressSpinner("Waiting", WithProgressOutput(out))
+	p.Start()
+	p.Success()
+	if strings.Contains(out.String(), "\x1b") || !strings.HasPrefix(out.String(), `{"event":"start","prompt":"Waiting"`) {
+		t.Errorf("Expected JSON output without escape sequences, got %q", out.String())
+	}
+}
+
+func TestLifecycleHooks(t *testing.T) {
+	var got []string
+	p := NewProgressBar("Hooked", WithProgressOutput(bytes.NewBuffer(nil)))
+	p.OnStart = func(e Event) { got = append(got, "start "+e.Prompt) }
+	p.OnTick = func(e Event) { got = append(got, fmt.Sprintf("tick %.1f", e.Pct)) }
+	p.OnFinish = func(e Event) { got = append(got, fmt.Sprintf("finish %s %s", e.Type, e.Message)) }
+	p.Start()
+	p.Update(0.5)
+	p.FailWith("timed out")
+
+	want := []string{"start Hooked", "tick 0.5", "finish fail timed out"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected hooks %v, got %v", want, got)
+	}
+}
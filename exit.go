@@ -0,0 +1,146 @@
+package clt
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+)
+
+var exitConfig = struct {
+	sync.Mutex
+	confirm bool
+	input   io.Reader
+	output  io.Writer
+	exit    func(code int)
+}{input: os.Stdin, output: os.Stdout, exit: os.Exit}
+
+// ConfirmExit sets whether Exit asks the user to confirm before quitting while
+// progress indicators are still running.  This guards long jobs against being
+// abandoned by accident.
+func ConfirmExit(confirm bool) {
+	exitConfig.Lock()
+	defer exitConfig.Unlock()
+	exitConfig.confirm = confirm
+}
+
+// Exit terminates the program with the given status code.  When ConfirmExit is enabled
+// and there are unfinished progress indicators, the user is asked whether to quit anyway.
+// If the user declines, Exit returns and the indicators continue where they left off.
+// Indicators still running when the program exits are marked as failed, and blocks
+// drawn by a Compositor are finished, so that the cursor is restored.
+func Exit(code int) {
+	exitConfig.Lock()
+	confirm, in, out, exit := exitConfig.confirm, exitConfig.input, exitConfig.output, exitConfig.exit
+	exitConfig.Unlock()
+
+	unfinished := runningProgress()
+	if confirm && len(unfinished) > 0 {
+		for _, p := range unfinished {
+			p.Pause()
+		}
+		tasks := "tasks are"
+		if len(unfinished) == 1 {
+			tasks = "task is"
+		}
+		prompt := fmt.Sprintf("%d %s still running. Quit anyway?", len(unfinished), tasks)
+		if !confirmPrompt(in, out, prompt, false) {
+			for _, p := range unfinished {
+				p.Resume()
+			}
+			return
+		}
+	}
+	for _, p := range unfinished {
+		p.Fail()
+	}
+	for _, c := range activeCompositors() {
+		c.Finish()
+	}
+	exit(code)
+}
+
+// confirmPrompt asks a yes or no question on out and reads the answer from in.  An
+// empty or unreadable answer returns def.
+func confirmPrompt(in io.Reader, out io.Writer, prompt string, def bool) bool {
+	choices := "y/N"
+	if def {
+		choices = "Y/n"
+	}
+	fmt.Fprintf(out, "\n%s  [%s]: ", prompt, choices)
+	resp, err := bufio.NewReader(in).ReadString('\n')
+	resp = strings.TrimSpace(resp)
+	if len(resp) == 0 && err != nil {
+		return def
+	}
+	switch {
+	case IsYes(resp):
+		return true
+	case IsNo(resp):
+		return false
+	}
+	return def
+}
+
+// HandleSignals cleans up the terminal when the program is interrupted with Ctrl-C or
+// terminated.  The lines of running progress indicators are cleared, the cursor is
+// shown, and anything else clt changed about the terminal is undone before the
+// program exits with the conventional status of 128 plus the signal number.  Call
+// the returned function to stop handling the signals.
+func HandleSignals() (stop func()) {
+	c := make(chan os.Signal, 1)
+	signal.Notify(c, os.Interrupt, syscall.SIGTERM)
+	done := make(chan struct{})
+	go func() {
+		select {
+		case sig := <-c:
+			// another signal during the cleanup ends the program straight away
+			signal.Stop(c)
+			cleanupAfterSignal(sig)
+		case <-done:
+		}
+	}()
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			signal.Stop(c)
+			close(done)
+		})
+	}
+}
+
+// cleanupAfterSignal restores the terminal and exits after sig was received
+func cleanupAfterSignal(sig os.Signal) {
+	for _, p := range runningProgress() {
+		p.mtx.Lock()
+		paused := p.paused && !p.json
+		p.mtx.Unlock()
+		// pausing clears the line and shows the cursor once the render goroutine
+		// has drawn its last frame.  Indicators paused already, such as for a
+		// prompt, are cleared the same way.
+		p.Pause()
+		if paused {
+			p.pause()
+		}
+		p.mtx.Lock()
+		p.unpin()
+		p.mtx.Unlock()
+	}
+	for _, c := range activeCompositors() {
+		c.Clear()
+	}
+	Repair()
+
+	exitConfig.Lock()
+	exit := exitConfig.exit
+	exitConfig.Unlock()
+	code := 1
+	if s, ok := sig.(syscall.Signal); ok {
+		code = 128 + int(s)
+	}
+	exit(code)
+}
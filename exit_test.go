@@ -0,0 +1,97 @@
+package clt
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+// withExitInput answers Exit's prompt with input and records the exit code instead of
+// exiting, until the test ends
+func withExitInput(t *testing.T, input string) (*bytes.Buffer, *int) {
+	var out bytes.Buffer
+	code := -1
+	exitConfig.Lock()
+	in, output, exit := exitConfig.input, exitConfig.output, exitConfig.exit
+	t.Cleanup(func() {
+		exitConfig.Lock()
+		defer exitConfig.Unlock()
+		exitConfig.input, exitConfig.output, exitConfig.exit = in, output, exit
+	})
+	exitConfig.input = strings.NewReader(input)
+	exitConfig.output = &out
+	exitConfig.exit = func(c int) { code = c }
+	exitConfig.Unlock()
+	return &out, &code
+}
+
+func TestExitConfirmation(t *testing.T) {
+	ConfirmExit(true)
+	defer ConfirmExit(false)
+
+	p := NewProgressSpinner("Long job")
+	p.output = bytes.NewBuffer(nil)
+	p.Interval = time.Second
+	p.Start()
+
+	out, code := withExitInput(t, "n\n")
+	Exit(2)
+	if *code != -1 {
+		t.Errorf("Exit should not exit when the user declines, got code %d", *code)
+	}
+	if want := "\n1 task is still running. Quit anyway?  [y/N]: "; out.String() != want {
+		t.Errorf("Expected prompt %q, got %q", want, out.String())
+	}
+
+	_, code = withExitInput(t, "y\n")
+	Exit(2)
+	if *code != 2 {
+		t.Errorf("Exit should exit with code 2 when confirmed, got %d", *code)
+	}
+	if err := p.Success(); err != ErrFinished {
+		t.Errorf("Running tasks should be finished on exit, got %v", err)
+	}
+}
+
+func TestCleanupAfterSignal(t *testing.T) {
+	withTerminalState(t)
+	_, code := withExitInput(t, "")
+
+	out := bytes.NewBuffer(nil)
+	p := NewProgressSpinner("Long job", WithProgressOutput(out))
+	p.Interval = time.Hour
+	p.Start()
+	defer p.Fail()
+
+	cleanupAfterSignal(os.Interrupt)
+	if *code != 130 {
+		t.Errorf("Expected exit status 130 after an interrupt, got %d", *code)
+	}
+	if !strings.HasSuffix(out.String(), "\r\x1b[2K\x1b[?25h") {
+		t.Errorf("Expected the line to be cleared and the cursor shown, got %q", out.String())
+	}
+
+	stop := HandleSignals()
+	stop()
+	stop()
+}
+
+func TestCleanupAfterSignalPaused(t *testing.T) {
+	withTerminalState(t)
+	withExitInput(t, "")
+
+	out := bytes.NewBuffer(nil)
+	p := NewProgressSpinner("Long job", WithProgressOutput(out))
+	p.Interval = time.Hour
+	p.Start()
+	defer p.Fail()
+	p.Pause()
+	out.Reset()
+
+	cleanupAfterSignal(os.Interrupt)
+	if want := "\r\x1b[2K\x1b[?25h"; out.String() != want {
+		t.Errorf("Expected the paused indicator to be cleared, got %q", out.String())
+	}
+}
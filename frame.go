@@ -0,0 +1,325 @@
+package clt
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+)
+
+// Compositor draws a block of lines that is replaced in place each time it is drawn,
+// the way progress indicators and their subtasks are, for building custom live
+// displays.  Lines printed with Println scroll above the block.  The cursor is hidden
+// while the block is shown and restored by Finish, Clear, Exit or HandleSignals, and
+// the block is redrawn after Pause for prompts.  Lines are cut to the width of the
+// terminal so that wrapping never moves the block out of place.
+//
+// Progress indicators running on the same terminal are drawn below the block, and
+// the lines they print, including their result when they finish, scroll above it.
+// When the output isn't a terminal, each new block is printed below the last one
+// without escape sequences.
+type Compositor struct {
+	mtx    sync.Mutex
+	output io.Writer
+	// live is true if the block is redrawn in place, and false if blocks are appended
+	live bool
+	// frame is the last block drawn, and shown is the number of its lines that are
+	// on the terminal.  printed is the last block appended when the output isn't live.
+	frame   []string
+	shown   int
+	printed []string
+	active  bool
+	paused  bool
+}
+
+// NewCompositor returns a compositor that draws to w, or os.Stdout if w is nil
+func NewCompositor(w io.Writer) *Compositor {
+	if w == nil {
+		w = os.Stdout
+	}
+	// terminals simulated by RenderWith are drawn like real ones
+	_, simulated := underlyingOutput(w).(*capabilityWriter)
+	return &Compositor{output: consoleOutput(w), live: simulated || isTerminal(w)}
+}
+
+// Draw replaces the block with lines.  Lines left over from a taller block are
+// cleared.
+func (c *Compositor) Draw(lines ...string) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	if !c.active {
+		c.active = true
+		addCompositor(c)
+		if isTerminal(c.output) {
+			setCursorHidden(true)
+		}
+	}
+	c.frame = append([]string(nil), lines...)
+	if !c.paused {
+		c.aside(c.draw)
+	}
+}
+
+// draw writes the frame over the lines shown, leaving the cursor at the start of the
+// line below it.  Must be called with the mutex held.
+func (c *Compositor) draw() {
+	if !c.live {
+		if !equalLines(c.printed, c.frame) {
+			for _, l := range c.frame {
+				fmt.Fprintf(c.output, "%s\n", l)
+			}
+			c.printed = c.frame
+		}
+		return
+	}
+	lines := c.frame
+	if width := terminalWidth(c.output); width > 0 {
+		lines = make([]string, len(c.frame))
+		for i, l := range c.frame {
+			lines[i] = Truncate(l, width)
+		}
+	}
+	fmt.Fprintf(c.output, "\x1b[?25l%s", composeBlock(c.shown, lines))
+	c.shown = len(lines)
+}
+
+// erase clears the lines shown, leaving the cursor at the start of the first one.
+// Must be called with the mutex held.
+func (c *Compositor) erase() {
+	if c.shown > 0 {
+		fmt.Fprintf(c.output, "\x1b[%dA\r\x1b[J", c.shown)
+	}
+	c.shown = 0
+}
+
+// aside pauses the progress indicators drawn below the block while f writes to the
+// terminal, so that they are drawn again below whatever f leaves there.  Indicators
+// that are already paused or finishing are left alone.  Must be called with the
+// mutex held.
+func (c *Compositor) aside(f func()) {
+	if !c.live {
+		f()
+		return
+	}
+	var paused []*Progress
+	for _, p := range runningProgress() {
+		p.mtx.Lock()
+		below := p.shares(c) && !p.paused && p.pinnedRow == 0
+		p.mtx.Unlock()
+		if below {
+			p.Pause()
+			paused = append(paused, p)
+		}
+	}
+	f()
+	for _, p := range paused {
+		p.Resume()
+	}
+}
+
+// Println prints a line above the block and draws the block again below it
+func (c *Compositor) Println(format string, args ...interface{}) {
+	text := fmt.Sprintf(format, args...)
+	c.printAbove(func() {
+		fmt.Fprintf(c.output, "%s\n", text)
+	})
+}
+
+// printAbove clears the block, calls print to write lines in its place and draws the
+// block again below them
+func (c *Compositor) printAbove(print func()) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	c.aside(func() {
+		if c.live {
+			c.erase()
+		}
+		print()
+		if c.active && !c.paused {
+			c.draw()
+		}
+	})
+}
+
+// Pause clears the block and shows the cursor so that the caller can ask a question.
+// Draw updates the block without showing it until Resume.
+func (c *Compositor) Pause() {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	if !c.active || c.paused {
+		return
+	}
+	c.paused = true
+	if c.live {
+		c.aside(func() {
+			c.erase()
+			fmt.Fprint(c.output, "\x1b[?25h")
+		})
+	}
+}
+
+// Resume draws the block again after Pause
+func (c *Compositor) Resume() {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	if !c.active || !c.paused {
+		return
+	}
+	c.paused = false
+	c.aside(c.draw)
+}
+
+// Finish leaves the last block on the terminal with the cursor below it
+func (c *Compositor) Finish() {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	if !c.active {
+		return
+	}
+	c.aside(func() {
+		if c.paused {
+			c.draw()
+		}
+		if c.live {
+			fmt.Fprint(c.output, "\x1b[?25h")
+		}
+	})
+	c.stop()
+}
+
+// Clear removes the block from the terminal, leaving the cursor where it started
+func (c *Compositor) Clear() {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	if !c.active {
+		return
+	}
+	if c.live {
+		c.aside(func() {
+			c.erase()
+			fmt.Fprint(c.output, "\x1b[?25h")
+		})
+	}
+	c.stop()
+}
+
+// stop forgets the block once it is finished or cleared.  Must be called with the
+// mutex held.
+func (c *Compositor) stop() {
+	c.active, c.paused, c.shown, c.frame, c.printed = false, false, 0, nil, nil
+	removeCompositor(c)
+	if len(runningProgress()) == 0 && len(activeCompositors()) == 0 {
+		setCursorHidden(false)
+	}
+}
+
+// composeBlock returns the output that replaces a block of shown lines above the
+// cursor with lines, leaving the cursor at the start of the line below them
+func composeBlock(shown int, lines []string) string {
+	var out bytes.Buffer
+	if shown > 0 {
+		fmt.Fprintf(&out, "\x1b[%dA", shown)
+	}
+	for _, l := range lines {
+		fmt.Fprintf(&out, "\r\x1b[2K%s\n", l)
+	}
+	// clear lines left over from a taller block and move back below the new one
+	if extra := shown - len(lines); extra > 0 {
+		out.WriteString(strings.Repeat("\r\x1b[2K\n", extra))
+		fmt.Fprintf(&out, "\x1b[%dA", extra)
+	}
+	return out.String()
+}
+
+// equalLines returns true if a and b are the same lines
+func equalLines(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// composeFrame returns the output that replaces a block of shown lines with lines,
+// leaving the cursor at the end of the last one
+func composeFrame(shown int, lines []string) string {
+	var out bytes.Buffer
+	if shown > 1 {
+		fmt.Fprintf(&out, "\x1b[%dA", shown-1)
+	}
+	for i, l := range lines {
+		if i > 0 {
+			out.WriteString("\n")
+		}
+		fmt.Fprintf(&out, "\r\x1b[2K%s", l)
+	}
+	// clear lines left over from a taller block and move back to the last line
+	if extra := shown - len(lines); extra > 0 {
+		out.WriteString(strings.Repeat("\n\r\x1b[2K", extra))
+		fmt.Fprintf(&out, "\x1b[%dA", extra)
+	}
+	return out.String()
+}
+
+// eraseFrame returns the output that clears a block of shown lines and leaves the
+// cursor at the start of its first line
+func eraseFrame(shown int) string {
+	if shown > 1 {
+		return fmt.Sprintf("\x1b[%dA\r\x1b[J", shown-1)
+	}
+	return "\r\x1b[J"
+}
+
+// compositors are the compositors that have drawn a block that isn't finished
+var compositors = struct {
+	sync.Mutex
+	c []*Compositor
+}{}
+
+func addCompositor(c *Compositor) {
+	compositors.Lock()
+	defer compositors.Unlock()
+	compositors.c = append(compositors.c, c)
+}
+
+func removeCompositor(c *Compositor) {
+	compositors.Lock()
+	defer compositors.Unlock()
+	for i, r := range compositors.c {
+		if r == c {
+			compositors.c = append(compositors.c[:i], compositors.c[i+1:]...)
+			return
+		}
+	}
+}
+
+// activeCompositors returns the compositors with a block on the terminal
+func activeCompositors() []*Compositor {
+	compositors.Lock()
+	defer compositors.Unlock()
+	return append([]*Compositor(nil), compositors.c...)
+}
+
+// shares returns true if p is drawn on the terminal that c draws its block on.  Must
+// be called with p's mutex held.
+func (p *Progress) shares(c *Compositor) bool {
+	return c.live && !p.json && p.parent == nil && sameOutput(p.output, c.output)
+}
+
+// compositor returns the most recently drawn compositor that p is drawn below, or
+// nil.  Must be called with p's mutex held.
+func (p *Progress) compositor() *Compositor {
+	active := activeCompositors()
+	for i := len(active) - 1; i >= 0; i-- {
+		if p.shares(active[i]) {
+			return active[i]
+		}
+	}
+	return nil
+}
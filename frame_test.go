@@ -0,0 +1,85 @@
+package clt
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestCompositor(t *testing.T) {
+	var out bytes.Buffer
+	c := NewCompositor(&out)
+	c.live = true
+	c.Draw("one", "two")
+	c.Draw("three")
+	c.Println("log %d", 1)
+	c.Finish()
+	want := "\x1b[?25l\r\x1b[2Kone\n\r\x1b[2Ktwo\n" +
+		"\x1b[?25l\x1b[2A\r\x1b[2Kthree\n\r\x1b[2K\n\x1b[1A" +
+		"\x1b[1A\r\x1b[Jlog 1\n" +
+		"\x1b[?25l\r\x1b[2Kthree\n" +
+		"\x1b[?25h"
+	if out.String() != want {
+		t.Errorf("Expected %q, got %q", want, out.String())
+	}
+	if n := len(activeCompositors()); n != 0 {
+		t.Errorf("Expected no active compositors after Finish, got %d", n)
+	}
+}
+
+func TestCompositorPause(t *testing.T) {
+	var out bytes.Buffer
+	c := NewCompositor(&out)
+	c.live = true
+	c.Draw("a", "b")
+	out.Reset()
+	c.Pause()
+	c.Draw("c")
+	if want := "\x1b[2A\r\x1b[J\x1b[?25h"; out.String() != want {
+		t.Errorf("Expected the block to be cleared and not drawn while paused, got %q", out.String())
+	}
+	out.Reset()
+	c.Resume()
+	c.Clear()
+	if want := "\x1b[?25l\r\x1b[2Kc\n\x1b[1A\r\x1b[J\x1b[?25h"; out.String() != want {
+		t.Errorf("Expected %q, got %q", want, out.String())
+	}
+}
+
+func TestCompositorPlain(t *testing.T) {
+	var out bytes.Buffer
+	c := NewCompositor(&out)
+	c.Draw("one", "two")
+	c.Draw("one", "two")
+	c.Pause()
+	c.Draw("three")
+	c.Println("log %d", 1)
+	c.Resume()
+	c.Finish()
+	if want := "one\ntwo\nlog 1\nthree\n"; out.String() != want {
+		t.Errorf("Expected changed blocks to be appended without escapes, got %q", out.String())
+	}
+}
+
+func TestCompositorProgress(t *testing.T) {
+	var out bytes.Buffer
+	c := NewCompositor(&out)
+	c.live = true
+	p := NewProgressSpinner("Working")
+	p.output = &out
+	p.Interval = time.Hour
+	p.Start()
+	c.Draw("block")
+	p.Println("log")
+	p.Success()
+	c.Finish()
+	// the spinner is drawn below the block, and its log line and result above it
+	want := "\x1b[?25l\rWorking[|]" +
+		"\r\x1b[2K\x1b[?25h\x1b[?25l\r\x1b[2Kblock\n\x1b[?25l\rWorking[|]" +
+		"\r\x1b[2K\x1b[?25h\x1b[1A\r\x1b[Jlog\n\x1b[?25l\r\x1b[2Kblock\n\x1b[?25l\rWorking[|]" +
+		"\r\x1b[2K\x1b[1A\r\x1b[J\x1b[?25h\rWorking[\x1b[32mOK\x1b[39m]\n\x1b[?25l\r\x1b[2Kblock\n" +
+		"\x1b[?25h"
+	if out.String() != want {
+		t.Errorf("Expected %q, got %q", want, out.String())
+	}
+}
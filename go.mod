@@ -6,5 +6,5 @@ require (
 	github.com/smartystreets/assertions v0.0.0-20180820201707-7c9eb446e3cf // indirect
 	github.com/smartystreets/goconvey v0.0.0-20180222194500-ef6db91d284a
 	golang.org/x/crypto v0.0.0-20180830192347-182538f80094
-	golang.org/x/sys v0.0.0-20180903190138-2b024373dcd9 // indirect
+	golang.org/x/sys v0.0.0-20180903190138-2b024373dcd9
 )
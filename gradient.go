@@ -0,0 +1,98 @@
+package clt
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// RGBColor is a color given by its red, green and blue components, such as the ends
+// of a Gradient
+type RGBColor struct {
+	R, G, B uint8
+}
+
+// Gradient renders text in colors that blend from one color to another across its
+// characters, such as for a banner.  Terminals without truecolor get the nearest of
+// their 256 or 16 colors, see SetQuantizer, and the text is unstyled when color is
+// turned off.
+func Gradient(text string, from RGBColor, to RGBColor) string {
+	return gradient(text, ColorProfile(), func(t float64) RGBColor {
+		return RGBColor{blend(from.R, to.R, t), blend(from.G, to.G, t), blend(from.B, to.B, t)}
+	})
+}
+
+// Rainbow renders text with each character further around the color wheel, from red
+// to violet
+func Rainbow(text string) string {
+	return gradient(text, ColorProfile(), func(t float64) RGBColor {
+		return hue(300 * t)
+	})
+}
+
+// gradient colors each visible character of text with the color at its position,
+// from 0 for the first to 1 for the last.  Runs of characters that come out the same
+// color share one escape sequence.
+func gradient(text string, depth ColorDepth, at func(t float64) RGBColor) string {
+	if !colorEnabled() {
+		return text
+	}
+	runes := []rune(text)
+	var b strings.Builder
+	last := ""
+	for i, r := range runes {
+		if r == ' ' || runeWidth(r) == 0 {
+			b.WriteRune(r)
+			continue
+		}
+		t := 0.0
+		if len(runes) > 1 {
+			t = float64(i) / float64(len(runes)-1)
+		}
+		if code := at(t).code(depth); code != last {
+			b.WriteString(code)
+			last = code
+		}
+		b.WriteRune(r)
+	}
+	if len(last) > 0 {
+		b.WriteString("\x1b[39m")
+	}
+	return b.String()
+}
+
+// code returns the escape sequence that sets the text to c, or the nearest color the
+// terminal can show
+func (c RGBColor) code(depth ColorDepth) string {
+	col := RGB(c.R, c.G, c.B).forDepth(depth)
+	if len(col.extended) > 0 {
+		return fmt.Sprintf("\x1b[%d;%sm", col.before, col.extended)
+	}
+	return fmt.Sprintf("\x1b[%dm", col.before)
+}
+
+// blend returns the value t of the way from a to b
+func blend(a uint8, b uint8, t float64) uint8 {
+	return uint8(math.Round(float64(a) + (float64(b)-float64(a))*t))
+}
+
+// hue returns the fully saturated color at h degrees around the color wheel
+func hue(h float64) RGBColor {
+	x := 1 - math.Abs(math.Mod(h/60, 2)-1)
+	var r, g, b float64
+	switch {
+	case h < 60:
+		r, g = 1, x
+	case h < 120:
+		r, g = x, 1
+	case h < 180:
+		g, b = 1, x
+	case h < 240:
+		g, b = x, 1
+	case h < 300:
+		r, b = x, 1
+	default:
+		r, b = 1, x
+	}
+	return RGBColor{uint8(math.Round(r * 255)), uint8(math.Round(g * 255)), uint8(math.Round(b * 255))}
+}
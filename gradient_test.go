@@ -0,0 +1,29 @@
+package clt
+
+import "testing"
+
+func TestGradient(t *testing.T) {
+	from, to := RGBColor{255, 0, 0}, RGBColor{0, 0, 255}
+	between := func(t float64) RGBColor {
+		return RGBColor{blend(from.R, to.R, t), blend(from.G, to.G, t), blend(from.B, to.B, t)}
+	}
+	tt := []struct {
+		Name  string
+		Depth ColorDepth
+		Want  string
+	}{
+		{Name: "truecolor", Depth: TrueColor, Want: "\x1b[38;2;255;0;0ma\x1b[38;2;170;0;85mb \x1b[38;2;0;0;255mc\x1b[39m"},
+		{Name: "256 colors", Depth: ANSI256, Want: "\x1b[38;5;196ma\x1b[38;5;125mb \x1b[38;5;21mc\x1b[39m"},
+		{Name: "16 colors", Depth: ANSI16, Want: "\x1b[91ma\x1b[35mb \x1b[34mc\x1b[39m"},
+	}
+	for _, tc := range tt {
+		t.Run(tc.Name, func(t *testing.T) {
+			if got := gradient("ab c", tc.Depth, between); got != tc.Want {
+				t.Errorf("Expected %q, got %q", tc.Want, got)
+			}
+		})
+	}
+	if got := Strip(Rainbow("rainbow")); got != "rainbow" {
+		t.Errorf("Expected the rainbow to keep its text, got %q", got)
+	}
+}
@@ -0,0 +1,94 @@
+package clt
+
+import "sync"
+
+// ProgressGroup is a progress bar that shows the combined completion of several
+// workers, such as the goroutines uploading the chunks of one file.  Each worker
+// reports its own count against its own total and the bar shows the sum of the counts
+// out of the sum of the totals, so larger pieces of work move the bar further.  Start
+// and terminate it like any other progress bar.  Counts are shown in human units,
+// such as (1.2 MB of 4.5 GB), after calling SetTotalBytes.
+type ProgressGroup struct {
+	*Progress
+
+	mtx     sync.Mutex
+	workers []*GroupWorker
+}
+
+// GroupWorker reports the progress of one worker in a ProgressGroup.  Its methods are
+// safe to call from multiple goroutines.
+type GroupWorker struct {
+	group     *ProgressGroup
+	completed int64
+	total     int64
+}
+
+// NewProgressGroup returns a progress bar that combines the progress of its workers
+func NewProgressGroup(format string, args ...interface{}) *ProgressGroup {
+	return &ProgressGroup{Progress: NewProgressBar(format, args...)}
+}
+
+// Worker adds a worker that completes total items of the group's work
+func (g *ProgressGroup) Worker(total int64) *GroupWorker {
+	w := &GroupWorker{group: g, total: total}
+	g.mtx.Lock()
+	g.workers = append(g.workers, w)
+	g.mtx.Unlock()
+	g.recalculate()
+	return w
+}
+
+// Add increments the number of items the worker has completed by n
+func (w *GroupWorker) Add(n int64) {
+	w.group.mtx.Lock()
+	w.completed = clampCount(w.completed+n, w.total)
+	w.group.mtx.Unlock()
+	w.group.recalculate()
+}
+
+// Update sets the number of items the worker has completed
+func (w *GroupWorker) Update(completed int64) {
+	w.group.mtx.Lock()
+	w.completed = clampCount(completed, w.total)
+	w.group.mtx.Unlock()
+	w.group.recalculate()
+}
+
+// SetTotal changes the number of items the worker completes, for work whose size is
+// only known once it starts
+func (w *GroupWorker) SetTotal(total int64) {
+	w.group.mtx.Lock()
+	w.total = total
+	w.completed = clampCount(w.completed, total)
+	w.group.mtx.Unlock()
+	w.group.recalculate()
+}
+
+// recalculate sets the bar to the combined counts of the workers
+func (g *ProgressGroup) recalculate() {
+	var completed, total int64
+	g.mtx.Lock()
+	for _, w := range g.workers {
+		completed += w.completed
+		total += w.total
+	}
+	// counts are stored before the group is unlocked so that concurrent workers
+	// can't replace a newer sum with an older one
+	p := g.Progress
+	p.mtx.Lock()
+	p.total = total
+	p.setCount(completed)
+	p.mtx.Unlock()
+	g.mtx.Unlock()
+	p.update(nil)
+}
+
+func clampCount(n int64, total int64) int64 {
+	switch {
+	case n < 0:
+		return 0
+	case n > total:
+		return total
+	}
+	return n
+}
@@ -0,0 +1,43 @@
+package clt
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestProgressGroup(t *testing.T) {
+	out := bytes.NewBuffer(nil)
+
+	g := NewProgressGroup("Uploading")
+	g.output = out
+	small, large := g.Worker(100), g.Worker(300)
+	g.Start()
+
+	var wg sync.WaitGroup
+	for _, w := range []*GroupWorker{small, large} {
+		wg.Add(1)
+		go func(w *GroupWorker) {
+			defer wg.Done()
+			for i := 0; i < 50; i++ {
+				w.Add(1)
+			}
+		}(w)
+	}
+	wg.Wait()
+	if got, want := g.frameState().countSuffix(), " (100 of 400)"; got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+
+	// the larger worker moves the bar further
+	small.Update(100)
+	if pct := g.frameState().pct; pct < 0.37 || pct > 0.38 {
+		t.Errorf("Expected the bar to be weighted by each worker's total, got %v", pct)
+	}
+	large.Add(1000)
+	g.Success()
+	if !strings.Contains(out.String(), "(400 of 400)") {
+		t.Errorf("Expected the combined counts on the bar, got %q", out.String())
+	}
+}
@@ -0,0 +1,43 @@
+package clt
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Bytes formats n bytes in SI units, such as 512 B or 1.4 GB
+func Bytes(n int64) string {
+	return humanize(n, []string{" B", " kB", " MB", " GB", " TB", " PB", " EB"})
+}
+
+// Count formats n with a suffix for thousands, millions and so on, such as 950 or
+// 12.3k
+func Count(n int64) string {
+	return humanize(n, []string{"", "k", "M", "B", "T", "Q", "Qi"})
+}
+
+// humanize scales n by powers of 1000 and appends the matching unit.  Values are
+// shown with one decimal place except for whole numbers and unscaled values, using
+// the decimal separator of the language set with SetLanguage.
+func humanize(n int64, units []string) string {
+	sign := ""
+	v := float64(n)
+	if n < 0 {
+		sign, v = "-", -v
+	}
+	if v < 1000 {
+		return sign + strconv.FormatInt(int64(v), 10) + units[0]
+	}
+	unit := 0
+	// round before comparing so that 999,999 becomes 1M rather than 1000k
+	for unit < len(units)-1 && v >= 999.95 {
+		v /= 1000
+		unit++
+	}
+	prec := 1
+	if strings.HasSuffix(strconv.FormatFloat(v, 'f', 1, 64), ".0") {
+		prec = 0
+	}
+	return fmt.Sprintf("%s%s%s", sign, FormatDecimal(v, prec), units[unit])
+}
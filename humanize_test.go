@@ -0,0 +1,44 @@
+package clt
+
+import (
+	"math"
+	"testing"
+)
+
+func TestBytes(t *testing.T) {
+	tt := []struct {
+		n    int64
+		want string
+	}{
+		{0, "0 B"},
+		{512, "512 B"},
+		{1000, "1 kB"},
+		{1450, "1.4 kB"},
+		{999999, "1 MB"},
+		{1400000000, "1.4 GB"},
+		{-2500, "-2.5 kB"},
+		{math.MaxInt64, "9.2 EB"},
+	}
+	for _, tc := range tt {
+		if got := Bytes(tc.n); got != tc.want {
+			t.Errorf("Bytes(%d): expected %q, got %q", tc.n, tc.want, got)
+		}
+	}
+}
+
+func TestCount(t *testing.T) {
+	tt := []struct {
+		n    int64
+		want string
+	}{
+		{950, "950"},
+		{12300, "12.3k"},
+		{4000000, "4M"},
+		{1250000000, "1.2B"},
+	}
+	for _, tc := range tt {
+		if got := Count(tc.n); got != tc.want {
+			t.Errorf("Count(%d): expected %q, got %q", tc.n, tc.want, got)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package clt
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// linkConfig records whether Link emits hyperlinks.  It is detected the first time a
+// link is made unless set with ForceHyperlinks.
+var linkConfig = struct {
+	sync.Mutex
+	set     bool
+	enabled bool
+}{}
+
+// Link returns text that opens url when clicked, using the OSC 8 escape sequence on
+// terminals that support it.  Elsewhere, including when standard output isn't a
+// terminal, the url follows the text as text (url).
+func Link(text string, url string) string {
+	if !hyperlinksEnabled() {
+		if text == url || len(text) == 0 {
+			return url
+		}
+		return fmt.Sprintf("%s (%s)", text, url)
+	}
+	return fmt.Sprintf("\x1b]8;;%s\x1b\\%s\x1b]8;;\x1b\\", url, text)
+}
+
+// ForceHyperlinks turns hyperlinks on or off regardless of the terminal.  By default
+// they are used by terminals known to support them, and FORCE_HYPERLINK set to 1 or 0
+// overrides the detection.
+func ForceHyperlinks(enabled bool) {
+	linkConfig.Lock()
+	defer linkConfig.Unlock()
+	linkConfig.set = true
+	linkConfig.enabled = enabled
+}
+
+func hyperlinksEnabled() bool {
+	linkConfig.Lock()
+	defer linkConfig.Unlock()
+	if !linkConfig.set {
+		linkConfig.set = true
+		linkConfig.enabled = detectHyperlinks(os.Getenv, isTerminal(os.Stdout))
+	}
+	return linkConfig.enabled
+}
+
+// hyperlinkTerminals are the values of TERM_PROGRAM of terminals that support OSC 8
+var hyperlinkTerminals = []string{"iTerm.app", "WezTerm", "vscode", "Hyper", "ghostty"}
+
+// detectHyperlinks returns whether the terminal in an environment looked up with
+// getenv supports hyperlinks
+func detectHyperlinks(getenv func(string) string, tty bool) bool {
+	if force := getenv("FORCE_HYPERLINK"); len(force) > 0 {
+		return force != "0"
+	}
+	if !tty || getenv("TERM") == "dumb" {
+		return false
+	}
+	for _, t := range hyperlinkTerminals {
+		if getenv("TERM_PROGRAM") == t {
+			return true
+		}
+	}
+	// VTE based terminals such as GNOME Terminal support them from 0.50
+	if v, err := strconv.Atoi(getenv("VTE_VERSION")); err == nil && v >= 5000 {
+		return true
+	}
+	switch {
+	case len(getenv("WT_SESSION")) > 0, len(getenv("KITTY_WINDOW_ID")) > 0:
+		return true
+	case strings.Contains(getenv("TERM"), "kitty"), strings.Contains(getenv("TERM"), "foot"):
+		return true
+	}
+	return false
+}
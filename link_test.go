@@ -0,0 +1,50 @@
+package clt
+
+import "testing"
+
+func TestLink(t *testing.T) {
+	defer ForceHyperlinks(false)
+
+	ForceHyperlinks(true)
+	if got, want := Link("docs", "https://example.com/docs"), "\x1b]8;;https://example.com/docs\x1b\\docs\x1b]8;;\x1b\\"; got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+	if got := Strip(Link("docs", "https://example.com/docs")); got != "docs" {
+		t.Errorf("Expected the link to show only its text, got %q", got)
+	}
+
+	ForceHyperlinks(false)
+	if got, want := Link("docs", "https://example.com/docs"), "docs (https://example.com/docs)"; got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+	if got, want := Link("https://example.com", "https://example.com"), "https://example.com"; got != want {
+		t.Errorf("Expected the url once, got %q", got)
+	}
+}
+
+func TestDetectHyperlinks(t *testing.T) {
+	tt := []struct {
+		Name string
+		Env  map[string]string
+		TTY  bool
+		Want bool
+	}{
+		{Name: "unknown terminal", TTY: true, Want: false},
+		{Name: "iterm", Env: map[string]string{"TERM_PROGRAM": "iTerm.app"}, TTY: true, Want: true},
+		{Name: "iterm pipe", Env: map[string]string{"TERM_PROGRAM": "iTerm.app"}, Want: false},
+		{Name: "new vte", Env: map[string]string{"VTE_VERSION": "6003"}, TTY: true, Want: true},
+		{Name: "old vte", Env: map[string]string{"VTE_VERSION": "4205"}, TTY: true, Want: false},
+		{Name: "windows terminal", Env: map[string]string{"WT_SESSION": "abc"}, TTY: true, Want: true},
+		{Name: "kitty", Env: map[string]string{"TERM": "xterm-kitty"}, TTY: true, Want: true},
+		{Name: "forced on", Env: map[string]string{"FORCE_HYPERLINK": "1"}, Want: true},
+		{Name: "forced off", Env: map[string]string{"FORCE_HYPERLINK": "0", "TERM_PROGRAM": "WezTerm"}, TTY: true, Want: false},
+	}
+	for _, tc := range tt {
+		t.Run(tc.Name, func(t *testing.T) {
+			getenv := func(key string) string { return tc.Env[key] }
+			if got := detectHyperlinks(getenv, tc.TTY); got != tc.Want {
+				t.Errorf("Expected %v, got %v", tc.Want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,135 @@
+package clt
+
+import (
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// localeFormat is how numbers and dates are written in a language
+type localeFormat struct {
+	// decimal separates the fraction of a number and group separates thousands
+	decimal string
+	group   string
+	// timeLayout shows a time later today and dateLayout one on another day
+	timeLayout string
+	dateLayout string
+}
+
+// defaultFormat is used until a language is set.  Thousands aren't grouped, so that
+// numbers are easy to copy and parse.
+var defaultFormat = localeFormat{decimal: ".", timeLayout: "15:04 MST", dateLayout: "Mon Jan 2 15:04 MST"}
+
+// localeFormats are the supported languages, looked up by language and region
+// first and then by language alone
+var localeFormats = map[string]localeFormat{
+	"en":    {decimal: ".", group: ",", timeLayout: "15:04 MST", dateLayout: "Mon Jan 2 15:04 MST"},
+	"en-us": {decimal: ".", group: ",", timeLayout: "3:04 PM MST", dateLayout: "Mon Jan 2 3:04 PM MST"},
+	"en-gb": {decimal: ".", group: ",", timeLayout: "15:04 MST", dateLayout: "Mon 2 Jan 15:04 MST"},
+	"de":    {decimal: ",", group: ".", timeLayout: "15:04 MST", dateLayout: "02.01.2006 15:04 MST"},
+	"de-ch": {decimal: ".", group: "'", timeLayout: "15:04 MST", dateLayout: "02.01.2006 15:04 MST"},
+	"es":    {decimal: ",", group: ".", timeLayout: "15:04 MST", dateLayout: "02/01/2006 15:04 MST"},
+	"it":    {decimal: ",", group: ".", timeLayout: "15:04 MST", dateLayout: "02/01/2006 15:04 MST"},
+	"pt":    {decimal: ",", group: ".", timeLayout: "15:04 MST", dateLayout: "02/01/2006 15:04 MST"},
+	"nl":    {decimal: ",", group: ".", timeLayout: "15:04 MST", dateLayout: "02-01-2006 15:04 MST"},
+	"da":    {decimal: ",", group: ".", timeLayout: "15:04 MST", dateLayout: "02.01.2006 15:04 MST"},
+	"fr":    {decimal: ",", group: " ", timeLayout: "15:04 MST", dateLayout: "02/01/2006 15:04 MST"},
+	"pl":    {decimal: ",", group: " ", timeLayout: "15:04 MST", dateLayout: "02.01.2006 15:04 MST"},
+	"ru":    {decimal: ",", group: " ", timeLayout: "15:04 MST", dateLayout: "02.01.2006 15:04 MST"},
+	"sv":    {decimal: ",", group: " ", timeLayout: "15:04 MST", dateLayout: "2006-01-02 15:04 MST"},
+	"fi":    {decimal: ",", group: " ", timeLayout: "15.04 MST", dateLayout: "2.1.2006 15.04 MST"},
+	"nb":    {decimal: ",", group: " ", timeLayout: "15:04 MST", dateLayout: "02.01.2006 15:04 MST"},
+	"ja":    {decimal: ".", group: ",", timeLayout: "15:04 MST", dateLayout: "2006/01/02 15:04 MST"},
+	"zh":    {decimal: ".", group: ",", timeLayout: "15:04 MST", dateLayout: "2006/01/02 15:04 MST"},
+	"ko":    {decimal: ".", group: ",", timeLayout: "15:04 MST", dateLayout: "2006.01.02 15:04 MST"},
+}
+
+// locale is the format selected with SetLanguage
+var locale = struct {
+	sync.RWMutex
+	format localeFormat
+}{format: defaultFormat}
+
+// SetLanguage formats the numbers, byte sizes and dates shown by widgets for the
+// language tag, such as de, pt-BR or a POSIX locale like fr_FR.UTF-8.  Pass
+// SystemLanguage() to follow the user's environment.  Unsupported languages, and an
+// empty tag, restore the default format, which doesn't group thousands.
+func SetLanguage(tag string) {
+	f := languageFormat(tag)
+	locale.Lock()
+	defer locale.Unlock()
+	locale.format = f
+}
+
+// SystemLanguage returns the language of the user's environment from LC_ALL,
+// LC_NUMERIC or LANG
+func SystemLanguage() string {
+	for _, env := range []string{"LC_ALL", "LC_NUMERIC", "LANG"} {
+		if v := os.Getenv(env); len(v) > 0 {
+			return v
+		}
+	}
+	return ""
+}
+
+// languageFormat returns the format for a language tag
+func languageFormat(tag string) localeFormat {
+	// fr_FR.UTF-8@euro is the same as fr-fr
+	if i := strings.IndexAny(tag, ".@"); i >= 0 {
+		tag = tag[:i]
+	}
+	tag = strings.ToLower(strings.Replace(tag, "_", "-", -1))
+	if f, ok := localeFormats[tag]; ok {
+		return f
+	}
+	if i := strings.Index(tag, "-"); i >= 0 {
+		if f, ok := localeFormats[tag[:i]]; ok {
+			return f
+		}
+	}
+	return defaultFormat
+}
+
+func currentFormat() localeFormat {
+	locale.RLock()
+	defer locale.RUnlock()
+	return locale.format
+}
+
+// FormatNumber formats n with the thousands separator of the language set with
+// SetLanguage, such as 1,234,567 or 1.234.567
+func FormatNumber(n int64) string {
+	return groupDigits(strconv.FormatInt(n, 10), currentFormat().group)
+}
+
+// FormatDecimal formats v with prec digits after the decimal separator of the
+// language set with SetLanguage, such as 1,234.5 or 1.234,5
+func FormatDecimal(v float64, prec int) string {
+	f := currentFormat()
+	s := strconv.FormatFloat(v, 'f', prec, 64)
+	whole, frac := s, ""
+	if i := strings.Index(s, "."); i >= 0 {
+		whole, frac = s[:i], f.decimal+s[i+1:]
+	}
+	return groupDigits(whole, f.group) + frac
+}
+
+// groupDigits inserts sep between each group of three digits of a whole number
+func groupDigits(s string, sep string) string {
+	sign := ""
+	if strings.HasPrefix(s, "-") {
+		sign, s = "-", s[1:]
+	}
+	if len(sep) == 0 || len(s) <= 3 {
+		return sign + s
+	}
+	var b strings.Builder
+	for i, d := range s {
+		if i > 0 && (len(s)-i)%3 == 0 {
+			b.WriteString(sep)
+		}
+		b.WriteRune(d)
+	}
+	return sign + b.String()
+}
@@ -0,0 +1,55 @@
+package clt
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSetLanguage(t *testing.T) {
+	defer SetLanguage("")
+
+	if got := FormatNumber(1234567); got != "1234567" {
+		t.Errorf("Expected the default format not to group thousands, got %s", got)
+	}
+
+	tt := []struct {
+		tag     string
+		number  string
+		decimal string
+		bytes   string
+	}{
+		{"en_US.UTF-8", "1,234,567", "1,234.5", "1.2 GB"},
+		{"de_DE.UTF-8@euro", "1.234.567", "1.234,5", "1,2 GB"},
+		{"fr-CA", "1 234 567", "1 234,5", "1,2 GB"},
+		{"de-CH", "1'234'567", "1'234.5", "1.2 GB"},
+		{"C", "1234567", "1234.5", "1.2 GB"},
+	}
+	for _, tc := range tt {
+		SetLanguage(tc.tag)
+		if got := FormatNumber(1234567); got != tc.number {
+			t.Errorf("%s: expected %s, got %s", tc.tag, tc.number, got)
+		}
+		if got := FormatDecimal(1234.5, 1); got != tc.decimal {
+			t.Errorf("%s: expected %s, got %s", tc.tag, tc.decimal, got)
+		}
+		if got := Bytes(1234500000); got != tc.bytes {
+			t.Errorf("%s: expected %s, got %s", tc.tag, tc.bytes, got)
+		}
+	}
+
+	SetLanguage("de")
+	if got, want := FormatNumber(-1234), "-1.234"; got != want {
+		t.Errorf("Expected %s, got %s", want, got)
+	}
+	p := NewProgressBar("Indexing")
+	p.SetTotal(20000)
+	p.setCount(1500)
+	if got, want := p.frameState().countSuffix(), " (1.500 of 20.000)"; got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+	c := NewCountdown(time.Date(2026, 3, 5, 1, 0, 0, 0, time.UTC), time.UTC)
+	c.now = func() time.Time { return time.Date(2026, 3, 2, 21, 48, 0, 0, time.UTC) }
+	if got, want := c.String(), "Next run at 05.03.2026 01:00 UTC (in 2d 3h)"; got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+}
@@ -0,0 +1,12 @@
+package clt
+
+import (
+	"os"
+	"testing"
+)
+
+func TestMain(m *testing.M) {
+	// the tests check the styled output, which is turned off when it isn't a terminal
+	ForceColor(true)
+	os.Exit(m.Run())
+}
@@ -0,0 +1,207 @@
+package clt
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// CommandHelp is structured help for a command.  The same metadata renders a short
+// usage message, a manpage-style page for the terminal, and roff source that can be
+// installed for man.
+type CommandHelp struct {
+	Name string
+	// Section is the manual section.  Defaults to 1.
+	Section     int
+	Summary     string
+	Synopsis    []string
+	Description string
+	Options     []HelpOption
+	Examples    []HelpExample
+}
+
+// HelpOption describes a flag accepted by a command, e.g. Flags: "-o, --output",
+// Arg: "FILE"
+type HelpOption struct {
+	Flags       string
+	Arg         string
+	Description string
+}
+
+// HelpExample is an example invocation of a command
+type HelpExample struct {
+	Description string
+	Command     string
+}
+
+// manIndent is the indentation of the body of each section, as man uses
+const manIndent = "       "
+
+// Usage returns a short usage message listing the synopsis and options
+func (h CommandHelp) Usage() string {
+	var out bytes.Buffer
+	for i, s := range h.Synopsis {
+		switch i {
+		case 0:
+			fmt.Fprintf(&out, "Usage: %s\n", s)
+		default:
+			fmt.Fprintf(&out, "       %s\n", s)
+		}
+	}
+	if len(h.Options) > 0 {
+		width := 0
+		for _, o := range h.Options {
+			if n := len(o.flag()); n > width {
+				width = n
+			}
+		}
+		out.WriteString("\nOptions:\n")
+		for _, o := range h.Options {
+			fmt.Fprintf(&out, "  %-*s  %s\n", width, o.flag(), o.Description)
+		}
+	}
+	return out.String()
+}
+
+// Man returns the help laid out like a manpage, with section headings and flags in
+// bold and arguments underlined
+func (h CommandHelp) Man() string {
+	var out bytes.Buffer
+	section := func(title string) {
+		if out.Len() > 0 {
+			out.WriteString("\n")
+		}
+		fmt.Fprintf(&out, "%s\n", Styled(Bold).ApplyTo(title))
+	}
+
+	section("NAME")
+	fmt.Fprintf(&out, "%s%s - %s\n", manIndent, h.Name, h.Summary)
+	if len(h.Synopsis) > 0 {
+		section("SYNOPSIS")
+		for _, s := range h.Synopsis {
+			fmt.Fprintf(&out, "%s%s\n", manIndent, h.boldName(s))
+		}
+	}
+	if len(h.Description) > 0 {
+		section("DESCRIPTION")
+		for _, l := range strings.Split(strings.TrimSpace(h.Description), "\n") {
+			switch {
+			case len(strings.TrimSpace(l)) == 0:
+				out.WriteString("\n")
+			default:
+				fmt.Fprintf(&out, "%s%s\n", manIndent, l)
+			}
+		}
+	}
+	if len(h.Options) > 0 {
+		section("OPTIONS")
+		for _, o := range h.Options {
+			flag := Styled(Bold).ApplyTo(o.Flags)
+			if len(o.Arg) > 0 {
+				flag += " " + Styled(Underline).ApplyTo(o.Arg)
+			}
+			fmt.Fprintf(&out, "%s%s\n%s       %s\n", manIndent, flag, manIndent, o.Description)
+		}
+	}
+	if len(h.Examples) > 0 {
+		section("EXAMPLES")
+		for i, e := range h.Examples {
+			if i > 0 {
+				out.WriteString("\n")
+			}
+			if len(e.Description) > 0 {
+				fmt.Fprintf(&out, "%s%s\n", manIndent, e.Description)
+			}
+			fmt.Fprintf(&out, "%s    $ %s\n", manIndent, e.Command)
+		}
+	}
+	return out.String()
+}
+
+// Roff returns the help as roff source using the man macros, suitable for installing
+// as <name>.<section> in a man directory
+func (h CommandHelp) Roff() string {
+	sec := h.Section
+	if sec <= 0 {
+		sec = 1
+	}
+	var out bytes.Buffer
+	fmt.Fprintf(&out, ".TH %s %d\n", roffEscape(strings.ToUpper(h.Name)), sec)
+	fmt.Fprintf(&out, ".SH NAME\n%s \\- %s\n", roffEscape(h.Name), roffEscape(h.Summary))
+	if len(h.Synopsis) > 0 {
+		out.WriteString(".SH SYNOPSIS\n")
+		for i, s := range h.Synopsis {
+			if i > 0 {
+				out.WriteString(".br\n")
+			}
+			fmt.Fprintf(&out, ".B %s\n", roffEscape(h.Name))
+			if rest := strings.TrimSpace(strings.TrimPrefix(s, h.Name)); len(rest) > 0 {
+				fmt.Fprintf(&out, "%s\n", roffLine(rest))
+			}
+		}
+	}
+	if len(h.Description) > 0 {
+		out.WriteString(".SH DESCRIPTION\n")
+		for _, l := range strings.Split(strings.TrimSpace(h.Description), "\n") {
+			switch {
+			case len(strings.TrimSpace(l)) == 0:
+				out.WriteString(".PP\n")
+			default:
+				fmt.Fprintf(&out, "%s\n", roffLine(l))
+			}
+		}
+	}
+	if len(h.Options) > 0 {
+		out.WriteString(".SH OPTIONS\n")
+		for _, o := range h.Options {
+			out.WriteString(".TP\n")
+			fmt.Fprintf(&out, "\\fB%s\\fR", roffEscape(o.Flags))
+			if len(o.Arg) > 0 {
+				fmt.Fprintf(&out, " \\fI%s\\fR", roffEscape(o.Arg))
+			}
+			fmt.Fprintf(&out, "\n%s\n", roffLine(o.Description))
+		}
+	}
+	if len(h.Examples) > 0 {
+		out.WriteString(".SH EXAMPLES\n")
+		for _, e := range h.Examples {
+			out.WriteString(".PP\n")
+			if len(e.Description) > 0 {
+				fmt.Fprintf(&out, "%s\n", roffLine(e.Description))
+			}
+			fmt.Fprintf(&out, ".PP\n.RS\n.nf\n%s\n.fi\n.RE\n", roffLine(e.Command))
+		}
+	}
+	return out.String()
+}
+
+// flag returns the flags and argument as shown in the usage message
+func (o HelpOption) flag() string {
+	if len(o.Arg) == 0 {
+		return o.Flags
+	}
+	return o.Flags + " " + o.Arg
+}
+
+// boldName shows the command name at the start of a synopsis line in bold
+func (h CommandHelp) boldName(s string) string {
+	if len(h.Name) == 0 || !strings.HasPrefix(s, h.Name) {
+		return s
+	}
+	return Styled(Bold).ApplyTo(h.Name) + s[len(h.Name):]
+}
+
+// roffEscape escapes the characters that roff would otherwise interpret
+func roffEscape(s string) string {
+	return strings.NewReplacer(`\`, `\e`, "-", `\-`).Replace(s)
+}
+
+// roffLine escapes a line of text, protecting a leading . or ' from being read
+// as a request
+func roffLine(s string) string {
+	s = roffEscape(s)
+	if strings.HasPrefix(s, ".") || strings.HasPrefix(s, "'") {
+		return `\&` + s
+	}
+	return s
+}
@@ -0,0 +1,36 @@
+package clt
+
+import (
+	"testing"
+
+	"github.com/BTBurke/snapshot"
+)
+
+var testCommandHelp = CommandHelp{
+	Name:        "mycmd",
+	Summary:     "deploy services",
+	Synopsis:    []string{"mycmd [options] SERVICE"},
+	Description: "Deploys SERVICE to the current context.\n\n.Files are uploaded first.",
+	Options: []HelpOption{
+		{Flags: "-o, --output", Arg: "FILE", Description: "write the plan to FILE"},
+		{Flags: "-v", Description: "verbose output"},
+	},
+	Examples: []HelpExample{
+		{Description: "Deploy the api service", Command: "mycmd api"},
+	},
+}
+
+func TestCommandHelpUsage(t *testing.T) {
+	want := "Usage: mycmd [options] SERVICE\n\nOptions:\n  -o, --output FILE  write the plan to FILE\n  -v                 verbose output\n"
+	if got := testCommandHelp.Usage(); got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+}
+
+func TestCommandHelpMan(t *testing.T) {
+	snapshot.Assert(t, []byte(testCommandHelp.Man()))
+}
+
+func TestCommandHelpRoff(t *testing.T) {
+	snapshot.Assert(t, []byte(testCommandHelp.Roff()))
+}
@@ -0,0 +1,133 @@
+package clt
+
+import (
+	"fmt"
+	"strings"
+)
+
+// markupStyles are the styles that can be named in a markup tag.  The names of the
+// theme, such as success and error, are looked up when the markup is formatted.
+var markupStyles = map[string]Styler{
+	"black": Black, "red": Red, "green": Green, "yellow": Yellow, "blue": Blue,
+	"magenta": Magenta, "cyan": Cyan, "white": White, "default": Default,
+	"bgblack": BgBlack, "bgred": BgRed, "bggreen": BgGreen, "bgyellow": BgYellow, "bgblue": BgBlue,
+	"bgmagenta": BgMagenta, "bgcyan": BgCyan, "bgwhite": BgWhite, "bgdefault": BgDefault,
+	"bold": Bold, "dim": Dim, "italic": Italic, "underline": Underline,
+	"blink": Blink, "reverse": Reverse, "hidden": Hidden, "strikethrough": Strikethrough, "overline": Overline,
+}
+
+// Sprintf formats like fmt.Sprintf after applying style tags in format, as in
+// Sprintf("deploy {green}succeeded{/} in {bold}%s{/}", d).  A tag names one or more
+// styles separated by commas, such as {bold,red} or {bgred,white}, and {/} ends the
+// most recent tag.  The styles of the theme can be named too: success, error,
+// warning, info, muted, prompt and reminder.  Write {{ and }} for literal braces.
+// Tags are only read from format, never from args, so that user data can't change
+// the styling.  Unknown tags are left as they are, a {/} without a tag is ignored and
+// tags still open at the end are closed.  Use SprintfStrict to report these mistakes
+// instead.
+func Sprintf(format string, args ...interface{}) string {
+	f, _ := markup(format, false)
+	return fmt.Sprintf(f, args...)
+}
+
+// SprintfStrict is Sprintf, but returns an error if format has an unknown or
+// unbalanced tag
+func SprintfStrict(format string, args ...interface{}) (string, error) {
+	f, err := markup(format, true)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf(f, args...), nil
+}
+
+// markup replaces the tags in format with the escape sequences of their styles
+func markup(format string, strict bool) (string, error) {
+	var out strings.Builder
+	var open []*Style
+	var names []string
+	for i := 0; i < len(format); i++ {
+		c := format[i]
+		switch {
+		case c == '{' && strings.HasPrefix(format[i:], "{{"), c == '}' && strings.HasPrefix(format[i:], "}}"):
+			out.WriteByte(c)
+			i++
+			continue
+		case c != '{':
+			out.WriteByte(c)
+			continue
+		}
+		end := strings.IndexByte(format[i:], '}')
+		if end < 0 {
+			if strict {
+				return "", fmt.Errorf("markup: tag at offset %d is not closed with }", i)
+			}
+			out.WriteString(format[i:])
+			break
+		}
+		tag := format[i+1 : i+end]
+		switch {
+		case tag == "/" && len(open) == 0:
+			if strict {
+				return "", fmt.Errorf("markup: {/} at offset %d has no tag to end", i)
+			}
+		case tag == "/":
+			out.WriteString(styleCodes(open[len(open)-1], false))
+			open, names = open[:len(open)-1], names[:len(names)-1]
+			// ending a color also resets the color of the tags around it
+			for _, sty := range open {
+				out.WriteString(styleCodes(sty, true))
+			}
+		default:
+			sty, ok := markupStyle(tag)
+			switch {
+			case !ok && strict:
+				return "", fmt.Errorf("markup: unknown style {%s} at offset %d", tag, i)
+			case !ok:
+				out.WriteString(format[i : i+end+1])
+			default:
+				out.WriteString(styleCodes(sty, true))
+				open, names = append(open, sty), append(names, tag)
+			}
+		}
+		i += end
+	}
+	if len(open) > 0 && strict {
+		return "", fmt.Errorf("markup: {%s} is never ended with {/}", names[len(names)-1])
+	}
+	for j := len(open) - 1; j >= 0; j-- {
+		out.WriteString(styleCodes(open[j], false))
+	}
+	return out.String(), nil
+}
+
+// markupStyle returns the style named by a tag such as bold,red
+func markupStyle(tag string) (*Style, bool) {
+	theme := CurrentTheme()
+	combined := &Style{}
+	for _, name := range strings.Split(tag, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		sty, ok := theme.byName(name)
+		if !ok {
+			s, ok := markupStyles[name]
+			if !ok {
+				return nil, false
+			}
+			sty = Styled(s)
+		}
+		combined.before += sty.before
+		combined.after = sty.after + combined.after
+	}
+	return combined, true
+}
+
+// styleCodes returns the escape sequence that starts or ends sty, with any % escaped
+// so that it passes through fmt.Sprintf.  It is empty when color is turned off.
+func styleCodes(sty *Style, start bool) string {
+	if !colorEnabled() {
+		return ""
+	}
+	if start {
+		return strings.Replace(sty.before, "%", "%%", -1)
+	}
+	return strings.Replace(sty.after, "%", "%%", -1)
+}
@@ -0,0 +1,48 @@
+package clt
+
+import "testing"
+
+func TestSprintf(t *testing.T) {
+	tt := []struct {
+		Name   string
+		Format string
+		Args   []interface{}
+		Want   string
+	}{
+		{Name: "tags", Format: "deploy {green}succeeded{/} in {bold}%s{/}", Args: []interface{}{"3s"}, Want: "deploy " + SStyled("succeeded", Green) + " in " + SStyled("3s", Bold)},
+		{Name: "combined", Format: "{bgred,white} FAIL {/}", Want: "\x1b[41m\x1b[37m FAIL \x1b[39m\x1b[49m"},
+		{Name: "nested colors", Format: "{red}a{green}b{/}c{/}", Want: "\x1b[31ma\x1b[32mb\x1b[39m\x1b[31mc\x1b[39m"},
+		{Name: "theme", Format: "{error}%d failed{/}", Args: []interface{}{2}, Want: CurrentTheme().Error.ApplyTo("2 failed")},
+		{Name: "escaped", Format: "{{literal}} {{/}}", Want: "{literal} {/}"},
+		{Name: "args are not markup", Format: "%s", Args: []interface{}{"{red}x{/}"}, Want: "{red}x{/}"},
+		{Name: "unknown tag", Format: "{nope}text{/}", Want: "{nope}text"},
+		{Name: "unclosed", Format: "{bold}text", Want: SStyled("text", Bold)},
+		{Name: "unterminated tag", Format: "a {bold", Want: "a {bold"},
+	}
+	for _, tc := range tt {
+		t.Run(tc.Name, func(t *testing.T) {
+			if got := Sprintf(tc.Format, tc.Args...); got != tc.Want {
+				t.Errorf("Expected %q, got %q", tc.Want, got)
+			}
+		})
+	}
+}
+
+func TestSprintfStrict(t *testing.T) {
+	if got, err := SprintfStrict("{bold}%d{/}", 1); err != nil || got != SStyled("1", Bold) {
+		t.Errorf("Expected %q, got %q, %v", SStyled("1", Bold), got, err)
+	}
+	for _, format := range []string{"{nope}x{/}", "{bold}x", "x{/}", "a {bold"} {
+		if _, err := SprintfStrict(format); err == nil {
+			t.Errorf("Expected an error for %q", format)
+		}
+	}
+}
+
+func TestSprintfNoColor(t *testing.T) {
+	defer ForceColor(true)
+	ForceColor(false)
+	if got := Sprintf("{green}ok{/} 100%%"); got != "ok 100%" {
+		t.Errorf("Expected the tags to be removed, got %q", got)
+	}
+}
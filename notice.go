@@ -0,0 +1,180 @@
+package clt
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// notices are banners and warnings printed to os.Stderr, such as update notices and
+// experimental and deprecated feature warnings
+var notices = struct {
+	sync.Mutex
+	output  io.Writer
+	pending []string
+}{output: os.Stderr}
+
+// showNotice prints a notice, holding it until no progress indicators are running
+func showNotice(notice string) {
+	notices.Lock()
+	defer notices.Unlock()
+	if len(runningProgress()) > 0 {
+		notices.pending = append(notices.pending, notice)
+		return
+	}
+	fmt.Fprint(notices.output, notice)
+}
+
+// flushNotices prints the notices held while progress indicators were running
+func flushNotices() {
+	notices.Lock()
+	defer notices.Unlock()
+	if len(runningProgress()) > 0 {
+		return
+	}
+	for _, n := range notices.pending {
+		fmt.Fprint(notices.output, n)
+	}
+	notices.pending = nil
+}
+
+// box draws lines inside a rounded border.  The style for each line is taken from
+// styles in order, and lines without one are left plain.
+func box(lines []string, border *Style, styles ...*Style) string {
+	width := 0
+	for _, l := range lines {
+		if n := displayWidth(l); n > width {
+			width = n
+		}
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "\n%s\n", border.ApplyTo("╭"+strings.Repeat("─", width+2)+"╮"))
+	for i, l := range lines {
+		text := l + strings.Repeat(" ", width-displayWidth(l))
+		if i < len(styles) && styles[i] != nil {
+			text = styles[i].ApplyTo(text)
+		}
+		fmt.Fprintf(&b, "%s %s %s\n", border.ApplyTo("│"), text, border.ApplyTo("│"))
+	}
+	fmt.Fprintf(&b, "%s\n", border.ApplyTo("╰"+strings.Repeat("─", width+2)+"╯"))
+	return b.String()
+}
+
+// ExperimentalEnv is the environment variable that turns off the warnings shown by
+// Experimental when it is set to any value
+const ExperimentalEnv = "CLT_NO_EXPERIMENTAL_WARNINGS"
+
+var experimental = struct {
+	sync.Mutex
+	shown map[string]bool
+}{shown: make(map[string]bool)}
+
+// Experimental warns the user that the feature name is experimental and may change or
+// be removed.  The warning is shown the first time each feature is used in a session, and
+// is turned off entirely by setting ExperimentalEnv.
+func Experimental(name string) {
+	if len(os.Getenv(ExperimentalEnv)) > 0 {
+		return
+	}
+	experimental.Lock()
+	shown := experimental.shown[name]
+	experimental.shown[name] = true
+	experimental.Unlock()
+	if shown {
+		return
+	}
+	lines := []string{
+		fmt.Sprintf("%s is experimental and may change or be removed in a future release.", name),
+		fmt.Sprintf("Set %s=1 to hide this warning.", ExperimentalEnv),
+	}
+	muted := CurrentTheme().Muted
+	showNotice(box(lines, muted, emphasized(muted, Italic), emphasized(muted, Italic)))
+}
+
+var deprecated = struct {
+	sync.Mutex
+	shown map[string]bool
+}{shown: make(map[string]bool)}
+
+// Deprecated warns the user that old is deprecated in favor of replacement and will be
+// removed at sunset, showing how long remains.  The warning for each deprecated name is
+// shown at most once per invocation.  A zero sunset leaves out the removal date and an
+// empty replacement leaves out the suggestion.
+func Deprecated(old string, replacement string, sunset time.Time) {
+	deprecated.Lock()
+	shown := deprecated.shown[old]
+	deprecated.shown[old] = true
+	deprecated.Unlock()
+	if shown {
+		return
+	}
+
+	msg := fmt.Sprintf("%s is deprecated", old)
+	switch remaining := time.Until(sunset); {
+	case sunset.IsZero():
+	case remaining > 0:
+		msg += fmt.Sprintf(" and will be removed on %s (%s)", sunset.Format("2006-01-02"), relativeTime(remaining))
+	default:
+		msg += fmt.Sprintf(" and was scheduled for removal on %s", sunset.Format("2006-01-02"))
+	}
+	msg += "."
+	if len(replacement) > 0 {
+		msg += fmt.Sprintf("  Use %s instead.", replacement)
+	}
+	showNotice(fmt.Sprintf("%s: %s\n", CurrentTheme().Warning.ApplyTo("Deprecated"), msg))
+}
+
+// relativeTime describes a duration in the future in the largest whole unit, e.g.
+// "in 3 days"
+func relativeTime(d time.Duration) string {
+	plural := func(n int, unit string) string {
+		if n == 1 {
+			return fmt.Sprintf("in 1 %s", unit)
+		}
+		return fmt.Sprintf("in %d %ss", n, unit)
+	}
+	day := 24 * time.Hour
+	switch {
+	case d < time.Hour:
+		return "in less than an hour"
+	case d < day:
+		return plural(int(d/time.Hour), "hour")
+	case d < 60*day:
+		return plural(int(d/day), "day")
+	case d < 365*day:
+		return plural(int(d/(30*day)), "month")
+	}
+	return plural(int(d/(365*day)), "year")
+}
+
+var tips = struct {
+	sync.Mutex
+	shown map[string]bool
+}{shown: make(map[string]bool)}
+
+// Tip shows a hint, such as a shortcut the user may not know about, unless the user
+// chose not to see it again with DismissTip.  Each tip is shown at most once per
+// invocation.  It returns true if the tip was shown or queued.
+func Tip(id string, text string) bool {
+	tips.Lock()
+	shown := tips.shown[id]
+	tips.shown[id] = true
+	tips.Unlock()
+	if _, dismissed := preferences().Get(tipKey(id)); shown || dismissed {
+		return false
+	}
+	showNotice(fmt.Sprintf("%s %s\n", CurrentTheme().Info.ApplyTo("Tip:"), CurrentTheme().Muted.ApplyTo(text)))
+	return true
+}
+
+// DismissTip remembers that the user doesn't want to see the tip id again
+func DismissTip(id string) error {
+	return preferences().Set(tipKey(id), "dismissed")
+}
+
+func tipKey(id string) string {
+	return "tip." + id
+}
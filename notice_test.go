@@ -0,0 +1,79 @@
+package clt
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func withNoticeOutput() *bytes.Buffer {
+	var out bytes.Buffer
+	notices.Lock()
+	notices.output = &out
+	notices.Unlock()
+	return &out
+}
+
+func TestExperimental(t *testing.T) {
+	out := withNoticeOutput()
+
+	Experimental("mycmd sync")
+	Experimental("mycmd sync")
+	if n := strings.Count(out.String(), "mycmd sync is experimental"); n != 1 {
+		t.Errorf("Expected one warning per session, got %d in %q", n, out.String())
+	}
+
+	os.Setenv(ExperimentalEnv, "1")
+	defer os.Unsetenv(ExperimentalEnv)
+	out.Reset()
+	Experimental("mycmd watch")
+	if out.Len() > 0 {
+		t.Errorf("Expected %s to hide the warning, got %q", ExperimentalEnv, out.String())
+	}
+}
+
+func TestBox(t *testing.T) {
+	got := box([]string{"上传", "ok"}, &Style{})
+	want := "\n╭──────╮\n│ 上传 │\n│ ok   │\n╰──────╯\n"
+	if got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+}
+
+func TestDeprecated(t *testing.T) {
+	out := withNoticeOutput()
+
+	sunset := time.Now().Add(10*24*time.Hour + time.Hour)
+	Deprecated("--old-flag", "--new-flag", sunset)
+	Deprecated("--old-flag", "--new-flag", sunset)
+	want := Styled(Yellow).ApplyTo("Deprecated") + ": --old-flag is deprecated and will be removed on " + sunset.Format("2006-01-02") + " (in 10 days).  Use --new-flag instead.\n"
+	if out.String() != want {
+		t.Errorf("Expected %q, got %q", want, out.String())
+	}
+
+	out.Reset()
+	Deprecated("legacy", "", time.Now().Add(-time.Hour))
+	if !strings.Contains(out.String(), "legacy is deprecated and was scheduled for removal on") {
+		t.Errorf("Expected a past sunset to be reported, got %q", out.String())
+	}
+}
+
+func TestRelativeTime(t *testing.T) {
+	tt := []struct {
+		d    time.Duration
+		want string
+	}{
+		{time.Minute, "in less than an hour"},
+		{90 * time.Minute, "in 1 hour"},
+		{3 * 24 * time.Hour, "in 3 days"},
+		{90 * 24 * time.Hour, "in 3 months"},
+		{800 * 24 * time.Hour, "in 2 years"},
+	}
+	for _, tc := range tt {
+		if got := relativeTime(tc.d); got != tc.want {
+			t.Errorf("relativeTime(%s): expected %q, got %q", tc.d, tc.want, got)
+		}
+	}
+}
@@ -0,0 +1,370 @@
+package clt
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+)
+
+// OutputMode selects how a command renders its results, for a flag such as --output
+type OutputMode string
+
+// Output modes
+const (
+	// OutputTable aligns the fields of each result in columns under a header
+	OutputTable OutputMode = "table"
+	// OutputWide is a table that also includes the columns tagged as wide
+	OutputWide OutputMode = "wide"
+	// OutputPlain writes one tab separated line per result without a header, for
+	// scripts that use cut or awk
+	OutputPlain OutputMode = "plain"
+	// OutputJSON writes the results as indented JSON
+	OutputJSON OutputMode = "json"
+	// OutputYAML writes the results as YAML
+	OutputYAML OutputMode = "yaml"
+)
+
+// OutputModes are the modes accepted by ParseOutputMode
+var OutputModes = []OutputMode{OutputTable, OutputWide, OutputPlain, OutputJSON, OutputYAML}
+
+// ParseOutputMode returns the output mode named by s, ignoring case
+func ParseOutputMode(s string) (OutputMode, error) {
+	for _, m := range OutputModes {
+		if strings.EqualFold(strings.TrimSpace(s), string(m)) {
+			return m, nil
+		}
+	}
+	return "", fmt.Errorf("unknown output mode %q, must be one of %s", s, joinModes())
+}
+
+// String returns the name of the mode
+func (m OutputMode) String() string {
+	return string(m)
+}
+
+// Set parses the mode from a flag so that an OutputMode can be used as a flag.Value
+func (m *OutputMode) Set(s string) error {
+	mode, err := ParseOutputMode(s)
+	if err != nil {
+		return err
+	}
+	*m = mode
+	return nil
+}
+
+// FormatEnv is the environment variable that sets the default mode of OutputFlag.
+// It is separate from OutputEnv so that JSON results are never mixed with the JSON
+// events of progress indicators.
+const FormatEnv = "CLT_FORMAT"
+
+// OutputFlag defines --output and its shorthand -o on fs and returns the mode it
+// selects.  The mode defaults to def, unless FormatEnv names a mode, in which case
+// the environment is used.  A mode given on the command line always wins.
+func OutputFlag(fs *flag.FlagSet, def OutputMode) *OutputMode {
+	mode := def
+	if env, err := ParseOutputMode(os.Getenv(FormatEnv)); err == nil {
+		mode = env
+	}
+	usage := fmt.Sprintf("output format (%s)", joinModes())
+	fs.Var(&mode, "output", usage)
+	fs.Var(&mode, "o", usage)
+	return &mode
+}
+
+func joinModes() string {
+	names := make([]string, len(OutputModes))
+	for i, m := range OutputModes {
+		names[i] = string(m)
+	}
+	return strings.Join(names, ", ")
+}
+
+// Printer renders results in one output mode.  Results are a struct, a pointer to
+// one, or a slice of them.  The human readable modes make a column of each exported
+// field, named by an `output` tag such as `output:"NAME"` or `output:"NAME,wide"` for
+// a column only shown in wide mode.  A field tagged `output:"-"` is left out.  The
+// machine readable modes use the usual json tags.
+type Printer interface {
+	Print(w io.Writer, v interface{}) error
+}
+
+// Printer returns the printer for the mode.  Unknown modes print a table.
+func (m OutputMode) Printer() Printer {
+	switch m {
+	case OutputJSON:
+		return jsonPrinter{}
+	case OutputYAML:
+		return yamlPrinter{}
+	case OutputPlain:
+		return plainPrinter{}
+	case OutputWide:
+		return tablePrinter{wide: true}
+	}
+	return tablePrinter{}
+}
+
+// Print writes v to w in the output mode
+func Print(w io.Writer, mode OutputMode, v interface{}) error {
+	return mode.Printer().Print(w, v)
+}
+
+type tablePrinter struct {
+	wide bool
+}
+
+func (p tablePrinter) Print(w io.Writer, v interface{}) error {
+	headers, rows := outputRows(v, p.wide)
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	if len(headers) > 0 {
+		fmt.Fprintln(tw, strings.Join(headers, "\t"))
+	}
+	for _, row := range rows {
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+	return tw.Flush()
+}
+
+type plainPrinter struct{}
+
+func (plainPrinter) Print(w io.Writer, v interface{}) error {
+	_, rows := outputRows(v, true)
+	for _, row := range rows {
+		if _, err := fmt.Fprintln(w, strings.Join(row, "\t")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type jsonPrinter struct{}
+
+func (jsonPrinter) Print(w io.Writer, v interface{}) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+type yamlPrinter struct{}
+
+func (yamlPrinter) Print(w io.Writer, v interface{}) error {
+	// encoding through JSON keeps the json tags and the order of the fields
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	node, err := decodeOrdered(dec)
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(w, yamlNode(node, ""))
+	return err
+}
+
+// outputRows returns the column headers and the cells of each result for the human
+// readable modes
+func outputRows(v interface{}, wide bool) ([]string, [][]string) {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr {
+		rv = rv.Elem()
+	}
+	if !rv.IsValid() {
+		return nil, nil
+	}
+	items := []reflect.Value{rv}
+	t := rv.Type()
+	if rv.Kind() == reflect.Slice || rv.Kind() == reflect.Array {
+		items = nil
+		for i := 0; i < rv.Len(); i++ {
+			items = append(items, rv.Index(i))
+		}
+		t = t.Elem()
+	}
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		// anything else is printed one value per line
+		var rows [][]string
+		for _, item := range items {
+			rows = append(rows, []string{fmt.Sprint(item.Interface())})
+		}
+		return nil, rows
+	}
+
+	var headers []string
+	var fields []int
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" {
+			continue
+		}
+		name, opts := f.Name, ""
+		if tag, ok := f.Tag.Lookup("output"); ok {
+			name, opts = tag, ""
+			if j := strings.Index(tag, ","); j >= 0 {
+				name, opts = tag[:j], tag[j+1:]
+			}
+		}
+		switch {
+		case name == "-":
+			continue
+		case opts == "wide" && !wide:
+			continue
+		case len(name) == 0:
+			name = f.Name
+		}
+		headers = append(headers, strings.ToUpper(name))
+		fields = append(fields, i)
+	}
+
+	var rows [][]string
+	for _, item := range items {
+		for item.Kind() == reflect.Ptr {
+			item = item.Elem()
+		}
+		row := make([]string, len(fields))
+		if item.IsValid() {
+			for j, i := range fields {
+				row[j] = fmt.Sprint(item.Field(i).Interface())
+			}
+		}
+		rows = append(rows, row)
+	}
+	return headers, rows
+}
+
+// yamlField is a key and value of a JSON object, kept in order
+type yamlField struct {
+	key   string
+	value interface{}
+}
+
+// decodeOrdered decodes the next JSON value, with objects as []yamlField so that
+// their fields stay in order
+func decodeOrdered(dec *json.Decoder) (interface{}, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch tok {
+	case json.Delim('{'):
+		fields := []yamlField{}
+		for dec.More() {
+			key, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			value, err := decodeOrdered(dec)
+			if err != nil {
+				return nil, err
+			}
+			fields = append(fields, yamlField{key: key.(string), value: value})
+		}
+		_, err := dec.Token()
+		return fields, err
+	case json.Delim('['):
+		items := []interface{}{}
+		for dec.More() {
+			item, err := decodeOrdered(dec)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, item)
+		}
+		_, err := dec.Token()
+		return items, err
+	}
+	return tok, nil
+}
+
+// yamlNode renders a decoded value as YAML lines starting with indent
+func yamlNode(node interface{}, indent string) string {
+	var b strings.Builder
+	switch n := node.(type) {
+	case []yamlField:
+		if len(n) == 0 {
+			return indent + "{}\n"
+		}
+		for _, f := range n {
+			fmt.Fprintf(&b, "%s%s:", indent, yamlScalar(f.key))
+			if isYAMLCollection(f.value) {
+				fmt.Fprintf(&b, "\n%s", yamlNode(f.value, indent+"  "))
+				continue
+			}
+			fmt.Fprintf(&b, " %s", yamlNode(f.value, ""))
+		}
+	case []interface{}:
+		if len(n) == 0 {
+			return indent + "[]\n"
+		}
+		for _, item := range n {
+			// the first line of each item follows its dash
+			child := yamlNode(item, indent+"  ")
+			fmt.Fprintf(&b, "%s- %s", indent, strings.TrimPrefix(child, indent+"  "))
+		}
+	default:
+		fmt.Fprintf(&b, "%s%s\n", indent, yamlScalar(node))
+	}
+	return b.String()
+}
+
+// isYAMLCollection returns true if node is an object or list that has entries
+func isYAMLCollection(node interface{}) bool {
+	switch n := node.(type) {
+	case []yamlField:
+		return len(n) > 0
+	case []interface{}:
+		return len(n) > 0
+	}
+	return false
+}
+
+// yamlScalar renders a JSON scalar, quoting strings that YAML would read as
+// something else
+func yamlScalar(v interface{}) string {
+	switch s := v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return strconv.FormatBool(s)
+	case json.Number:
+		return s.String()
+	case string:
+		if yamlNeedsQuotes(s) {
+			return strconv.Quote(s)
+		}
+		return s
+	}
+	return fmt.Sprint(v)
+}
+
+func yamlNeedsQuotes(s string) bool {
+	switch strings.ToLower(s) {
+	case "", "null", "~", "true", "false", "yes", "no", "on", "off":
+		return true
+	}
+	if _, err := strconv.ParseFloat(s, 64); err == nil {
+		return true
+	}
+	if strings.TrimSpace(s) != s || strings.ContainsAny(s[:1], "-?:,[]{}#&*!|>'\"%@`") {
+		return true
+	}
+	if strings.Contains(s, ": ") || strings.Contains(s, " #") {
+		return true
+	}
+	for _, r := range s {
+		if r == '\\' || !strconv.IsPrint(r) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,82 @@
+package clt
+
+import (
+	"bytes"
+	"flag"
+	"testing"
+)
+
+type outputServer struct {
+	Name    string   `json:"name"`
+	Region  string   `json:"region"`
+	IP      string   `json:"ip" output:"IP,wide"`
+	Tags    []string `json:"tags,omitempty" output:"-"`
+	private int
+}
+
+var outputServers = []outputServer{
+	{Name: "web-1", Region: "us-east-1", IP: "10.0.0.1", Tags: []string{"web", "on"}},
+	{Name: "db", Region: "eu-west-1", IP: "10.0.0.2"},
+}
+
+func TestPrintModes(t *testing.T) {
+	tests := []struct {
+		mode OutputMode
+		want string
+	}{
+		{OutputTable, "NAME   REGION\nweb-1  us-east-1\ndb     eu-west-1\n"},
+		{OutputWide, "NAME   REGION     IP\nweb-1  us-east-1  10.0.0.1\ndb     eu-west-1  10.0.0.2\n"},
+		{OutputPlain, "web-1\tus-east-1\t10.0.0.1\ndb\teu-west-1\t10.0.0.2\n"},
+		{OutputJSON, "[\n  {\n    \"name\": \"web-1\",\n    \"region\": \"us-east-1\",\n    \"ip\": \"10.0.0.1\",\n    \"tags\": [\n      \"web\",\n      \"on\"\n    ]\n  },\n  {\n    \"name\": \"db\",\n    \"region\": \"eu-west-1\",\n    \"ip\": \"10.0.0.2\"\n  }\n]\n"},
+		{OutputYAML, "- name: web-1\n  region: us-east-1\n  ip: 10.0.0.1\n  tags:\n    - web\n    - \"on\"\n- name: db\n  region: eu-west-1\n  ip: 10.0.0.2\n"},
+	}
+	for _, tc := range tests {
+		out := bytes.NewBuffer(nil)
+		if err := Print(out, tc.mode, outputServers); err != nil {
+			t.Fatal(err)
+		}
+		if out.String() != tc.want {
+			t.Errorf("%s: expected:\n%s\ngot:\n%s", tc.mode, tc.want, out.String())
+		}
+	}
+
+	out := bytes.NewBuffer(nil)
+	Print(out, OutputTable, &outputServers[1])
+	if got, want := out.String(), "NAME  REGION\ndb    eu-west-1\n"; got != want {
+		t.Errorf("Expected a single result as one row %q, got %q", want, got)
+	}
+}
+
+func TestOutputFlag(t *testing.T) {
+	t.Setenv(FormatEnv, "")
+	t.Setenv(OutputEnv, "json")
+	fs := flag.NewFlagSet("list", flag.ContinueOnError)
+	mode := OutputFlag(fs, OutputTable)
+	if *mode != OutputTable {
+		t.Errorf("Expected %s to leave the default alone, got %v", OutputEnv, *mode)
+	}
+	if err := fs.Parse([]string{"-o", "YAML"}); err != nil || *mode != OutputYAML {
+		t.Errorf("Expected the flag to select yaml, got %v %v", *mode, err)
+	}
+	fs = flag.NewFlagSet("list", flag.ContinueOnError)
+	fs.SetOutput(bytes.NewBuffer(nil))
+	OutputFlag(fs, OutputTable)
+	if err := fs.Parse([]string{"--output", "xml"}); err == nil {
+		t.Errorf("Expected an unknown mode to be rejected")
+	}
+
+	// the environment overrides the default but not the flag
+	t.Setenv(FormatEnv, "json")
+	fs = flag.NewFlagSet("list", flag.ContinueOnError)
+	mode = OutputFlag(fs, OutputTable)
+	fs.Parse(nil)
+	if *mode != OutputJSON {
+		t.Errorf("Expected %s to select json, got %v", FormatEnv, *mode)
+	}
+	fs = flag.NewFlagSet("list", flag.ContinueOnError)
+	mode = OutputFlag(fs, OutputTable)
+	fs.Parse([]string{"-output=wide"})
+	if *mode != OutputWide {
+		t.Errorf("Expected the flag to win over %s, got %v", FormatEnv, *mode)
+	}
+}
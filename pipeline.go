@@ -0,0 +1,152 @@
+package clt
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// Stage is one command of a Pipeline
+type Stage struct {
+	// Name labels the status line of the stage.  It defaults to the name of the
+	// command.
+	Name string
+	// Cmd is the command to run.  The output of each stage is connected to the input
+	// of the next, so only the Stdin of the first stage and the Stdout of the last are
+	// used.  If Stderr is nil, the lines the command writes to it are printed above
+	// the status lines, after the name of the stage.
+	Cmd *exec.Cmd
+}
+
+// Pipeline runs the commands of the stages together, like dump | compress | upload in
+// a shell.  Each stage has a status line that counts the bytes it has passed to the
+// next and shows how long it ran, so that it is clear where the time is going.  Like
+// a shell with pipefail set, the error is that of the last stage to fail.
+func Pipeline(stages ...Stage) error {
+	return runPipeline(os.Stdout, stages)
+}
+
+func runPipeline(out io.Writer, stages []Stage) error {
+	if len(stages) == 0 {
+		return nil
+	}
+	names := make([]string, len(stages))
+	for i, st := range stages {
+		names[i] = st.Name
+		if len(names[i]) == 0 {
+			names[i] = filepath.Base(st.Cmd.Path)
+		}
+	}
+	p := NewProgressSpinner("%s", strings.Join(names, " | "), WithProgressOutput(out), WithElapsedTime())
+	counters := make([]*Progress, len(stages))
+	for i := range stages {
+		counters[i] = p.Child("%s", names[i])
+		counters[i].counting, counters[i].bytes, counters[i].elapsed = true, true, true
+	}
+
+	// data between stages passes through a pipe so that it can be counted
+	readers := make([]*io.PipeReader, len(stages)-1)
+	writers := make([]*io.PipeWriter, len(stages)-1)
+	for i := range readers {
+		readers[i], writers[i] = io.Pipe()
+		stages[i].Cmd.Stdout = &countingWriter{w: writers[i], p: counters[i]}
+		stages[i+1].Cmd.Stdin = readers[i]
+	}
+	last := len(stages) - 1
+	if stages[last].Cmd.Stdout != nil {
+		stages[last].Cmd.Stdout = &countingWriter{w: stages[last].Cmd.Stdout, p: counters[last]}
+	}
+	stderrs := make([]*stageOutput, len(stages))
+	for i, st := range stages {
+		if st.Cmd.Stderr == nil {
+			stderrs[i] = &stageOutput{name: names[i], p: p}
+			st.Cmd.Stderr = stderrs[i]
+		}
+	}
+
+	p.Start()
+	errs := make([]error, len(stages))
+	var wg sync.WaitGroup
+	for i := range stages {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			counters[i].Start()
+			err := stages[i].Cmd.Start()
+			if err == nil {
+				err = stages[i].Cmd.Wait()
+			}
+			if stderrs[i] != nil {
+				stderrs[i].flush()
+			}
+			// the next stage reaches the end of its input, and the previous one
+			// stops with an error instead of blocking on a stage that has exited
+			if i < last {
+				writers[i].Close()
+			}
+			if i > 0 {
+				readers[i-1].Close()
+			}
+			counters[i].Stop(err)
+			if err != nil {
+				errs[i] = fmt.Errorf("%s: %w", names[i], err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	var err error
+	for _, e := range errs {
+		if e != nil {
+			err = e
+		}
+	}
+	p.Stop(err)
+	return err
+}
+
+// countingWriter adds the bytes written through it to the count of a progress indicator
+type countingWriter struct {
+	w io.Writer
+	p *Progress
+}
+
+func (c *countingWriter) Write(b []byte) (int, error) {
+	n, err := c.w.Write(b)
+	c.p.Add(int64(n))
+	return n, err
+}
+
+// stageOutput prints the lines written to it above the status lines of a pipeline,
+// after the name of the stage that wrote them
+type stageOutput struct {
+	name    string
+	p       *Progress
+	partial []byte
+}
+
+func (s *stageOutput) Write(b []byte) (int, error) {
+	s.partial = append(s.partial, b...)
+	for {
+		i := bytes.IndexByte(s.partial, '\n')
+		if i < 0 {
+			break
+		}
+		s.p.Println("%s: %s", s.name, strings.TrimSuffix(string(s.partial[:i]), "\r"))
+		s.partial = s.partial[i+1:]
+	}
+	return len(b), nil
+}
+
+// flush prints the last line written if it didn't end with a newline
+func (s *stageOutput) flush() {
+	if len(s.partial) > 0 {
+		s.p.Println("%s: %s", s.name, s.partial)
+		s.partial = nil
+	}
+}
@@ -0,0 +1,56 @@
+package clt
+
+import (
+	"bytes"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+func TestPipeline(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh is needed to run the stages")
+	}
+	out := new(bytes.Buffer)
+	var result bytes.Buffer
+	upper := exec.Command("sh", "-c", "tr a-z A-Z")
+	upper.Stdout = &result
+	err := runPipeline(out, []Stage{
+		{Name: "dump", Cmd: exec.Command("sh", "-c", "printf 'hello world'")},
+		{Cmd: upper},
+	})
+	if err != nil {
+		t.Fatalf("Expected the pipeline to succeed, got %v", err)
+	}
+	if result.String() != "HELLO WORLD" {
+		t.Errorf("Expected the output of every stage, got %q", result.String())
+	}
+	for _, want := range []string{"dump | sh", "dump[" + Styled(Green).ApplyTo("OK") + "] 11 B", "sh[" + Styled(Green).ApplyTo("OK") + "] 11 B"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("Expected the status lines to contain %q, got %q", want, out.String())
+		}
+	}
+}
+
+func TestPipelineFailure(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh is needed to run the stages")
+	}
+	out := new(bytes.Buffer)
+	err := runPipeline(out, []Stage{
+		{Name: "dump", Cmd: exec.Command("sh", "-c", "printf data")},
+		{Name: "compress", Cmd: exec.Command("sh", "-c", "cat >/dev/null; echo 'disk full' >&2; printf 'giving up' >&2; exit 3")},
+		{Name: "upload", Cmd: exec.Command("sh", "-c", "cat >/dev/null")},
+	})
+	if err == nil || !strings.HasPrefix(err.Error(), "compress: exit status 3") {
+		t.Errorf("Expected the failed stage to be reported, got %v", err)
+	}
+	if !strings.Contains(out.String(), "compress["+Styled(Red).ApplyTo("FAIL")+"]") {
+		t.Errorf("Expected the failed stage to be marked, got %q", out.String())
+	}
+	for _, want := range []string{"compress: disk full\n", "compress: giving up\n"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("Expected the stage's errors to be printed, got %q", out.String())
+		}
+	}
+}
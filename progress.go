@@ -1,8 +1,12 @@
 package clt
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"io"
+	"math"
+	"math/bits"
 	"os"
 	"strings"
 	"sync"
@@ -12,6 +16,8 @@ import (
 const (
 	success int = iota
 	fail
+	// custom results are finished with Finish
+	custom
 )
 
 const (
@@ -20,6 +26,40 @@ const (
 	loading
 )
 
+const (
+	idle int = iota
+	running
+	finished
+)
+
+var (
+	// ErrNotStarted is returned when a progress indicator is terminated before
+	// it is started
+	ErrNotStarted = errors.New("progress indicator has not been started")
+	// ErrFinished is returned when a progress indicator is terminated more than once
+	ErrFinished = errors.New("progress indicator has already finished")
+	// ErrTimedOut is returned when a progress indicator is terminated after it was
+	// failed by FailAfter
+	ErrTimedOut = errors.New("progress indicator timed out")
+)
+
+// Overflow is the policy for rendering a progress indicator whose prompt is too long
+// to fit on one line of the terminal
+type Overflow int
+
+// Overflow policies
+const (
+	// OverflowTruncate shortens the prompt with an ellipsis so the indicator
+	// always fits on one line
+	OverflowTruncate Overflow = iota
+	// OverflowWrap writes the full prompt and lets the terminal wrap the line
+	OverflowWrap
+	// OverflowMarquee scrolls the prompt horizontally within the available
+	// width while a spinner or loading indicator runs so that the whole prompt
+	// can be read over time.  Bars and the final result line are truncated.
+	OverflowMarquee
+)
+
 // Spinner is a set of unicode strings that show a moving progress indication in the terminal
 type Spinner []string
 
@@ -42,38 +82,120 @@ type Progress struct {
 	// the prompt and the ..., does not include status indicator
 	// at the end (e.g, the spinner, FAIL, OK, or XX%)
 	DisplayLength int
+	// AutoSize scales the display to fill the width of the terminal,
+	// re-measured on every render so that resizing the window is picked
+	// up.  DisplayLength is used when the output is not a terminal.
+	AutoSize bool
+	// Interval is the time between animation frames of spinners and
+	// loading indicators.  Defaults to 100ms for spinners and 250ms for
+	// loading indicators.
+	Interval time.Duration
+	// Overflow sets how a prompt that is too long for the terminal is rendered.
+	// Defaults to OverflowTruncate.
+	Overflow Overflow
+	// SpinnerStyle styles the animated glyph of spinners and loading indicators,
+	// e.g. Styled(Cyan)
+	SpinnerStyle *Style
+	// PromptStyle styles the prompt of spinners and loading indicators,
+	// e.g. Styled(Bold)
+	PromptStyle *Style
+	// BarColor returns the style of the filled portion of a bar at pct complete,
+	// e.g. ThresholdColors.  The bar is not colored if it is nil.
+	BarColor func(pct float64) *Style
+	// OnStart, OnTick and OnFinish are called with the event when the indicator
+	// starts, changes, and ends with any result.  They run on the goroutine that
+	// changed the indicator, alongside any displays added with Mirror.
+	OnStart  func(e Event)
+	OnTick   func(e Event)
+	OnFinish func(e Event)
 
-	style     int
-	cf        chan float64
-	c         chan int
+	style    int
+	state    int
+	result   int
+	paused   bool
+	pct      float64
+	current  int64
+	total    int64
+	bytes    bool
+	detail   string
+	status   string
+	counting bool
+	unit     string
+	// tallied is when a counter last sent its count to be drawn
+	tallied   time.Time
+	displays  []Display
+	message   string
+	c         chan frameState
 	spinsteps Spinner
 	delay     time.Duration
 	output    io.Writer
-	wg        sync.WaitGroup
-	mtx       sync.Mutex
+	tty       *bool
+	json      bool
+	elapsed   bool
+	smooth    bool
+	blocks    bool
+	pinned    bool
+	// pinnedRow is the terminal row a pinned indicator is drawn on, or 0
+	pinnedRow int
+	priority  Priority
+	usage     *usageSampler
+	// finishStyle is the style of the status set with Finish
+	finishStyle *Style
+	watchdog    *time.Timer
+	timedOut    bool
+	// cancel ends the context of the work run with Go
+	cancel   context.CancelFunc
+	started  time.Time
+	stopped  time.Time
+	wg       sync.WaitGroup
+	inflight sync.WaitGroup
+	mtx      sync.Mutex
+	parent   *Progress
+	children []*Progress
+	lines    int
 }
 
 // NewProgressSpinner returns a new spinner with prompt <message>
-// display length defaults to 30.
+// display length defaults to 30.  ProgressOptions may be passed
+// anywhere in args and are applied after the defaults.
 func NewProgressSpinner(format string, args ...interface{}) *Progress {
-	return &Progress{
+	args, opts := progressOptions(args)
+	p := &Progress{
 		style:         spinner,
 		Prompt:        fmt.Sprintf(format, args...),
 		DisplayLength: 30,
+		AutoSize:      true,
+		Interval:      100 * time.Millisecond,
 		output:        os.Stdout,
 		spinsteps:     Wheel,
 	}
+	return p.apply(opts)
+}
+
+// NewProgressCounter returns a spinner for work with no known total that shows a live
+// count, such as Scanning files...[|] 12403 found.  The count is grouped for the language
+// set with SetLanguage, so it is shown as 12,403 for English.  Report progress with
+// Increment, Add or UpdateCount, and set the word after the count with WithCountUnit.
+func NewProgressCounter(format string, args ...interface{}) *Progress {
+	p := NewProgressSpinner(format, args...)
+	p.counting = true
+	return p
 }
 
 // NewProgressBar returns a new progress bar with prompt <message>
-// display length defaults to 20
+// display length defaults to 20.  ProgressOptions may be passed
+// anywhere in args and are applied after the defaults.
 func NewProgressBar(format string, args ...interface{}) *Progress {
-	return &Progress{
+	args, opts := progressOptions(args)
+	p := &Progress{
 		style:         bar,
 		Prompt:        fmt.Sprintf(format, args...),
 		DisplayLength: 20,
+		AutoSize:      true,
+		smooth:        true,
 		output:        os.Stdout,
 	}
+	return p.apply(opts)
 }
 
 // NewLoadingMessage creates a spinning loading indicator followed by a message.
@@ -83,213 +205,1412 @@ func NewProgressBar(format string, args ...interface{}) *Progress {
 // prevent flickering when the remote call finishes quickly.  If you finish your call
 // and call Success() or Failure() within the delay period, the loading indicator
 // will never be shown.
-func NewLoadingMessage(message string, spinner Spinner, delay time.Duration) *Progress {
-	return &Progress{
+func NewLoadingMessage(message string, spinner Spinner, delay time.Duration, opts ...ProgressOption) *Progress {
+	p := &Progress{
 		style:         loading,
 		Prompt:        message,
 		DisplayLength: 0,
+		Interval:      250 * time.Millisecond,
 		spinsteps:     spinner,
 		output:        os.Stdout,
 		delay:         delay,
 	}
+	return p.apply(opts)
+}
+
+// ProgressOption optionally configures aspects of a progress indicator
+type ProgressOption func(p *Progress)
+
+// WithProgressOutput renders the progress indicator to w instead of os.Stdout, e.g. to
+// keep progress on os.Stderr so that it doesn't mix with output that is piped elsewhere
+func WithProgressOutput(w io.Writer) ProgressOption {
+	return func(p *Progress) {
+		p.output = w
+	}
+}
+
+// WithSpinner uses the spinner s for spinners and loading indicators
+func WithSpinner(s Spinner) ProgressOption {
+	return func(p *Progress) {
+		if len(s) > 0 {
+			p.spinsteps = s
+		}
+	}
+}
+
+// WithLength sets a fixed display length instead of sizing the display to the width
+// of the terminal
+func WithLength(n int) ProgressOption {
+	return func(p *Progress) {
+		p.DisplayLength = n
+		p.AutoSize = false
+	}
+}
+
+// WithWriterIsTTY overrides the detection of whether the output is a terminal.  When
+// false, the display is never sized to or truncated for the terminal.  When true, the
+// output is treated as a terminal of 80 columns if its size can't be determined.
+func WithWriterIsTTY(isTTY bool) ProgressOption {
+	return func(p *Progress) {
+		p.tty = &isTTY
+	}
+}
+
+// WithElapsedTime shows the time since the indicator started next to it, such as
+// Building...[|] 01:23.  It is updated on every frame, so bars with elapsed time are
+// redrawn between updates.
+func WithElapsedTime() ProgressOption {
+	return func(p *Progress) {
+		p.elapsed = true
+	}
+}
+
+// WithResourceUsage shows the CPU and memory used by the process next to the
+// indicator, such as [CPU  12% RSS  48 MB], sampled about once a second.  It isn't
+// shown on platforms where the usage can't be read.
+func WithResourceUsage() ProgressOption {
+	return func(p *Progress) {
+		p.usage = &usageSampler{}
+	}
+}
+
+// WithSmoothing sets whether a bar on a terminal glides to each new value over a few
+// frames instead of jumping, so that sparse updates don't look stalled.  It is on by
+// default.  Output that isn't a terminal always shows the exact value.
+func WithSmoothing(smooth bool) ProgressOption {
+	return func(p *Progress) {
+		p.smooth = smooth
+	}
+}
+
+// WithPartialBlocks draws bars with the Unicode eighth-block characters so that each
+// column shows eight steps of progress.  Short bars advance smoothly instead of in
+// large chunks.
+func WithPartialBlocks() ProgressOption {
+	return func(p *Progress) {
+		p.blocks = true
+	}
+}
+
+// WithPinnedLine keeps the indicator on the bottom row of the terminal while other
+// output scrolls above it, like the progress of many build tools.  The rows above are
+// made a scroll region, so anything written to stdout or stderr while the indicator
+// runs stays out of its way.  Indicators with subtasks, and output that isn't a
+// terminal, are drawn in place as usual.
+func WithPinnedLine() ProgressOption {
+	return func(p *Progress) {
+		p.pinned = true
+	}
+}
+
+// Priority decides how often an indicator is redrawn when many are running at once
+type Priority int
+
+// Priorities for WithPriority
+const (
+	// PriorityLow indicators, such as background gauges, are redrawn every fourth
+	// frame while the display is busy
+	PriorityLow Priority = -1
+	// PriorityNormal indicators are redrawn every frame.  It is the default.
+	PriorityNormal Priority = 0
+	// PriorityHigh indicators are redrawn every frame like normal ones, for marking
+	// the ones that matter most such as work that is failing
+	PriorityHigh Priority = 1
+)
+
+// busyIndicators is the number of running indicators from which the display is busy
+// and low priority indicators are redrawn less often
+const busyIndicators = 4
+
+// WithPriority sets how often the indicator is redrawn when many are running.  Results
+// are always drawn as soon as an indicator finishes, whatever its priority.
+func WithPriority(priority Priority) ProgressOption {
+	return func(p *Progress) {
+		p.priority = priority
+	}
+}
+
+// WithCountUnit sets the word shown after the count of a counter, such as found or
+// files
+func WithCountUnit(unit string) ProgressOption {
+	return func(p *Progress) {
+		p.unit = unit
+	}
+}
+
+// WithJSONOutput writes progress as JSON lines, one per event, instead of drawing
+// the indicator.  It is also turned on for every indicator by setting OutputEnv to
+// json.
+func WithJSONOutput() ProgressOption {
+	return func(p *Progress) {
+		p.json = true
+	}
+}
+
+// progressOptions separates ProgressOptions from the format arguments passed to a
+// constructor
+func progressOptions(args []interface{}) ([]interface{}, []ProgressOption) {
+	var opts []ProgressOption
+	var rest []interface{}
+	for _, arg := range args {
+		switch opt := arg.(type) {
+		case ProgressOption:
+			opts = append(opts, opt)
+		default:
+			rest = append(rest, arg)
+		}
+	}
+	return rest, opts
+}
+
+func (p *Progress) apply(opts []ProgressOption) *Progress {
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p
+}
+
+// SetOutput renders the progress indicator and its subtasks to w instead of os.Stdout.
+// It should be called before Start.
+func (p *Progress) SetOutput(w io.Writer) {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	p.output = w
+	for _, c := range p.children {
+		c.SetOutput(w)
+	}
+}
+
+// width returns the width of the terminal the indicator is rendered to, or 0 if the
+// output is not a terminal
+func (p *Progress) width() int {
+	switch {
+	case p.tty == nil:
+		return terminalWidth(p.output)
+	case !*p.tty:
+		return 0
+	}
+	if width := terminalWidth(p.output); width > 0 {
+		return width
+	}
+	return 80
+}
+
+// height returns the height of the terminal the indicator is rendered to, or 0 if
+// the output is not a terminal
+func (p *Progress) height() int {
+	switch {
+	case p.tty == nil:
+		return terminalHeight(p.output)
+	case !*p.tty:
+		return 0
+	}
+	if height := terminalHeight(p.output); height > 0 {
+		return height
+	}
+	return 24
 }
 
 // Start launches a Goroutine to render the progress bar or spinner
 // and returns control to the caller for further processing.  Spinner
-// will update automatically every 250ms until Success() or Fail() is
+// will update automatically every Interval until Success() or Fail() is
 // called.  Bars will update by calling Update(<pct_complete>).  You
 // must always finally call either Success() or Fail() to terminate
 // the go routine.
 func (p *Progress) Start() {
-	p.wg.Add(1)
-	switch p.style {
-	case spinner:
-		p.c = make(chan int)
-		go renderSpinner(p, p.c)
-	case bar:
-		p.cf = make(chan float64, 2)
-		go renderBar(p, p.cf)
-		p.cf <- 0.0
-	case loading:
-		p.c = make(chan int)
-		go renderLoading(p, p.c)
+	p.start(0.0)
+}
+
+// StartAt launches the progress bar like Start, but the first render shows the bar
+// at pct complete instead of 0%.  This is useful for resumed downloads or retried
+// jobs where some of the work is already done.  Spinners and loading indicators
+// ignore the initial value.  It returns the progress bar so that it can be chained
+// with the constructor, e.g. NewProgressBar("Downloading").StartAt(0.35).
+func (p *Progress) StartAt(pct float64) *Progress {
+	p.start(pct)
+	return p
+}
+
+func (p *Progress) start(initial float64) {
+	switch {
+	case initial < 0.0:
+		initial = 0.0
+	case initial > 1.0:
+		initial = 1.0
+	}
+	p.mtx.Lock()
+	if p.state != idle {
+		p.mtx.Unlock()
+		return
+	}
+	p.state = running
+	p.started = time.Now()
+	p.displays = append(mirrorAllDisplays(), p.displays...)
+	if p.style == bar {
+		p.pct = initial
+	}
+	if p.output == nil {
+		p.output = os.Stdout
+	}
+	if os.Getenv(OutputEnv) == "json" {
+		p.json = true
+	}
+	if p.json {
+		p.displays = append(p.displays, JSONDisplay(p.output))
+	}
+	// subtasks are drawn by their parent
+	if p.parent != nil {
+		p.mtx.Unlock()
+		p.parent.refresh()
+		p.emit(EventStart)
+		return
 	}
+	addRunning(p)
+	if !p.json && isTerminal(p.output) {
+		setCursorHidden(true)
+	}
+	p.output = consoleOutput(p.output)
+	// the first snapshot is queued before the mutex is released so that it is
+	// always drawn before any update
+	p.c = make(chan frameState, 2)
+	p.c <- p.frameState()
+	p.mtx.Unlock()
+
+	p.wg.Add(1)
+	go render(p, p.c)
+	p.emit(EventStart)
 }
 
 // Success should be called on a progress bar or spinner
-// after completion is successful
-func (p *Progress) Success() {
-	switch p.style {
-	case spinner:
-		p.c <- success
-	case bar:
-		p.cf <- -1.0
-	case loading:
-		p.c <- success
+// after completion is successful.  It returns ErrNotStarted if
+// the indicator was never started and ErrFinished if it has already
+// been terminated, in which case it does nothing.
+func (p *Progress) Success() error {
+	return p.finish(success, "")
+}
+
+// SuccessWith is like Success but replaces the OK trailer (or 100% for bars) with
+// a custom message, keeping the success styling
+func (p *Progress) SuccessWith(format string, args ...interface{}) error {
+	return p.finish(success, fmt.Sprintf(format, args...))
+}
+
+// Fail should be called on a progress bar or spinner
+// if a failure occurs.  Like Success, it is safe to call more than once.
+func (p *Progress) Fail() error {
+	return p.finish(fail, "")
+}
+
+// FailWith is like Fail but replaces the FAIL trailer with a custom message,
+// keeping the failure styling
+func (p *Progress) FailWith(format string, args ...interface{}) error {
+	return p.finish(fail, fmt.Sprintf(format, args...))
+}
+
+// Finish ends the indicator with a status other than success or failure, such as
+// WARN in yellow or SKIPPED in dim, drawn in place of the OK or FAIL trailer.  A nil
+// style draws the status unstyled.  Like Success, it is safe to call more than once.
+func (p *Progress) Finish(status string, style *Style) error {
+	p.mtx.Lock()
+	if p.state == running {
+		p.finishStyle = style
 	}
+	p.mtx.Unlock()
+	return p.finish(custom, status)
+}
 
-	p.wg.Wait()
+// FailAfter fails the indicator with a TIMEOUT trailer if it hasn't been terminated
+// within d, so that a hung operation doesn't leave it running with the cursor hidden.
+// Calling it again restarts the window, and later calls to Success or Fail return
+// ErrTimedOut once it has fired.
+func (p *Progress) FailAfter(d time.Duration) {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	if p.state == finished {
+		return
+	}
+	if p.watchdog != nil {
+		p.watchdog.Stop()
+	}
+	p.watchdog = time.AfterFunc(d, func() {
+		p.terminate(fail, fmt.Sprintf("TIMEOUT after %s", d), true)
+	})
+}
 
-	switch p.style {
-	case spinner:
-		close(p.c)
-	case bar:
-		close(p.cf)
-	case loading:
-		close(p.c)
+// Go starts the indicator, runs f, and terminates the indicator with Success or Fail
+// based on the error f returns, which is returned.  The context passed to f is
+// canceled if the indicator is terminated first, such as by FailAfter, in which case
+// ErrTimedOut is returned if f doesn't return an error of its own.  If f panics, the
+// indicator fails and the cursor is restored before the panic continues.
+func (p *Progress) Go(f func(ctx context.Context) error) (err error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	p.mtx.Lock()
+	p.cancel = cancel
+	p.mtx.Unlock()
+
+	p.Start()
+	defer func() {
+		if r := recover(); r != nil {
+			p.Fail()
+			panic(r)
+		}
+	}()
+	err = f(ctx)
+	if serr := p.Stop(err); err == nil && serr == ErrTimedOut {
+		return serr
 	}
+	return err
 }
 
-// Fail should be called on a progress bar or spinner
-// if a failure occurs
-func (p *Progress) Fail() {
-	switch p.style {
-	case spinner:
-		p.c <- fail
-	case bar:
-		p.cf <- -2.0
-	// loading only has one termination state
-	case loading:
-		p.c <- success
+// Stop terminates the progress indicator based on the result of the work it
+// tracks.  A nil err calls Success and a non-nil err calls Fail.
+func (p *Progress) Stop(err error) error {
+	if err != nil {
+		return p.Fail()
+	}
+	return p.Success()
+}
+
+// finish moves the indicator to the finished state and renders the result.  Only
+// the first call after Start has any effect.
+func (p *Progress) finish(result int, message string) error {
+	return p.terminate(result, message, false)
+}
+
+// terminate is finish for the watchdog set with FailAfter, which records whether it
+// was the one that ended the indicator
+func (p *Progress) terminate(result int, message string, timeout bool) error {
+	p.mtx.Lock()
+	switch p.state {
+	case idle:
+		p.mtx.Unlock()
+		return ErrNotStarted
+	case finished:
+		timedOut := p.timedOut
+		p.mtx.Unlock()
+		if timedOut {
+			return ErrTimedOut
+		}
+		return ErrFinished
+	}
+	if p.watchdog != nil {
+		p.watchdog.Stop()
 	}
+	if p.cancel != nil {
+		p.cancel()
+	}
+	p.state = finished
+	p.timedOut = timeout
+	p.stopped = time.Now()
+	p.result = result
+	p.paused = false
+	p.message = message
+	s := p.frameState()
+	p.mtx.Unlock()
+
+	if p.parent != nil {
+		p.parent.refresh()
+		p.emitResult(result)
+		return nil
+	}
+	removeRunning(p)
 
+	// no new snapshots can be sent once finished, so wait for the ones
+	// in flight to reach the renderer before ending it
+	p.inflight.Wait()
+	p.c <- s
 	p.wg.Wait()
+	close(p.c)
+	if len(runningProgress()) == 0 && len(activeCompositors()) == 0 {
+		setCursorHidden(false)
+	}
 
-	switch p.style {
-	case spinner:
-		close(p.c)
-	case bar:
-		close(p.cf)
-	case loading:
-		close(p.c)
+	p.emitResult(result)
+	flushNotices()
+	return nil
+}
+
+func (p *Progress) emitResult(result int) {
+	switch result {
+	case success:
+		p.emit(EventSuccess)
+	case custom:
+		p.emit(EventFinish)
+	default:
+		p.emit(EventFail)
 	}
 }
 
-// Start launches a Goroutine to render the progress bar or spinner
-// and returns control to the caller for further processing.  Spinner
-// will update automatically every 250ms until Success() or Fail() is
-// called.  Bars will update by calling Update(<pct_complete>).  You
-// must always finally call either Success() or Fail() to terminate
-// the go routine.
+// UpdatePrompt changes the prompt of the progress indicator.  The new prompt is
+// measured and drawn on the next frame, so it can be called at any time while the
+// indicator is running.
 func (p *Progress) UpdatePrompt(prompt string) {
-	p.wg.Add(1)
-	defer p.wg.Done()
 	p.mtx.Lock()
-	defer p.mtx.Unlock()
 	p.Prompt = prompt
+	p.mtx.Unlock()
+	if !p.update(nil) {
+		p.emit(EventTick)
+	}
+}
+
+// frameState is a snapshot of an indicator at one point in time.  The render
+// goroutine only draws from snapshots, so a change made while a frame is being
+// drawn is picked up whole on the next one.
+type frameState struct {
+	prompt  string
+	pct     float64
+	current int64
+	total   int64
+	bytes   bool
+	detail  string
+	status  string
+	// counting and unit are set for counters
+	counting bool
+	unit     string
+	state    int
+	result   int
+	message  string
+	style    *Style
+	// ack is set for requests to the render goroutine and is closed once the
+	// request has been handled
+	ack     chan struct{}
+	request int
+	// text is the output to print for printRequest
+	text string
+}
+
+// requests handled by the render goroutine
+const (
+	pauseRequest int = iota + 1
+	resumeRequest
+	printRequest
+)
+
+// frameState returns a snapshot of the current state.  Must be called with the
+// mutex held.
+func (p *Progress) frameState() frameState {
+	return frameState{
+		prompt:   p.Prompt,
+		pct:      p.pct,
+		current:  p.current,
+		total:    p.total,
+		bytes:    p.bytes,
+		detail:   p.detail,
+		status:   p.status,
+		counting: p.counting,
+		unit:     p.unit,
+		state:    p.state,
+		result:   p.result,
+		message:  p.message,
+		style:    p.finishStyle,
+	}
 }
 
-func renderSpinner(p *Progress, c chan int) {
+// outcome returns the trailer drawn for a finished spinner and its style
+func (s frameState) outcome() (string, *Style) {
+	switch s.result {
+	case fail:
+		return s.trailer("FAIL"), CurrentTheme().Error
+	case custom:
+		if s.style == nil {
+			return s.message, Styled()
+		}
+		return s.message, s.style
+	}
+	return s.trailer("OK"), CurrentTheme().Success
+}
+
+// trailer returns the custom message set by SuccessWith or FailWith, or def if
+// there isn't one
+func (s frameState) trailer(def string) string {
+	if len(s.message) == 0 {
+		return def
+	}
+	return s.message
+}
+
+// SetStatus shows s after the spinner, such as Deploying...[|] uploading layer 3/7,
+// without changing the prompt.  An empty string removes it.  It can be called at any
+// time while the spinner is running and is drawn on the next frame.  The status is
+// not shown once the spinner finishes.
+func (p *Progress) SetStatus(s string) {
+	p.mtx.Lock()
+	p.status = s
+	p.mtx.Unlock()
+	if !p.update(nil) {
+		p.emit(EventTick)
+	}
+}
+
+// update applies f to the state of a running indicator and sends the new state to
+// the render goroutine.  It returns false without calling f before Start or after
+// the indicator has finished.  f is called with the mutex held and may be nil.
+func (p *Progress) update(f func()) bool {
+	p.mtx.Lock()
+	if p.state != running {
+		p.mtx.Unlock()
+		return false
+	}
+	if f != nil {
+		f()
+	}
+	if p.parent != nil {
+		p.mtx.Unlock()
+		p.parent.refresh()
+		p.emit(EventTick)
+		return true
+	}
+	p.inflight.Add(1)
+	defer p.inflight.Done()
+	s := p.frameState()
+	p.mtx.Unlock()
+	p.c <- s
+	p.emit(EventTick)
+	return true
+}
+
+// render is the render goroutine for every style of indicator.  It draws each
+// snapshot it receives and, for animated indicators, redraws the latest one every
+// Interval until it receives a finished snapshot.
+func render(p *Progress, c chan frameState) {
 	defer p.wg.Done()
-	if p.output == nil {
-		p.output = os.Stdout
+	s := <-c
+
+	// JSON output is written by its display, so requests are handled but nothing
+	// is drawn
+	if p.json {
+		for ; s.state != finished; s = <-c {
+			p.handle(&s, new(bool))
+		}
+		return
 	}
+
+	def := 100 * time.Millisecond
+	if p.style == loading {
+		def = 250 * time.Millisecond
+		// delay to prevent flickering
+		// calling Success or Failure within delay will shortcircuit the loading indicator
+		if p.delay > 0 {
+			delay := time.After(p.delay)
+			for waiting := true; waiting; {
+				select {
+				case s = <-c:
+					if s.state == finished {
+						return
+					}
+					p.handle(&s, new(bool))
+				case <-delay:
+					waiting = false
+				}
+			}
+		}
+	}
+
+	// bars only redraw on updates, except that subtasks are animated between updates
+	var tick <-chan time.Time
+	animated := p.style != bar || p.hasChildren() || p.elapsed || p.usage != nil
+	if animated {
+		tick = time.After(p.frameInterval(def))
+	}
+
+	// bars on a terminal glide toward each new value
 	p.mtx.Lock()
-	promptLen := len(p.Prompt)
+	// terminals simulated by RenderWith show the exact value so that they can be
+	// snapshotted
+	_, simulated := p.output.(*capabilityWriter)
+	smoothing := p.style == bar && p.smooth && len(p.children) == 0 && p.width() > 0 && !simulated
 	p.mtx.Unlock()
-	dotLen := p.DisplayLength - promptLen
-	if dotLen < 3 {
-		dotLen = 3
+	shown := s.pct
+	var glide <-chan time.Time
+
+	p.mtx.Lock()
+	if p.pinned && p.style != loading && len(p.children) == 0 && p.height() > 2 {
+		p.pin(p.height())
 	}
-	for i := 0; ; i++ {
-		select {
-		case result := <-c:
-			switch result {
-			case success:
-				p.mtx.Lock()
-				fmt.Fprintf(p.output, "\x1b[?25h\r%s[%s]\n", p.Prompt, Styled(Green).ApplyTo("OK"))
-				p.mtx.Unlock()
-			case fail:
-				p.mtx.Lock()
-				fmt.Fprintf(p.output, "\x1b[?25h\r%s[%s]\n", p.Prompt, Styled(Red).ApplyTo("FAIL"))
+	p.mtx.Unlock()
+
+	paused := false
+	var drawn frameState
+	for frame := 0; ; {
+		p.handle(&s, &paused)
+
+		current := s
+		if smoothing && s.state != finished {
+			shown = approach(shown, s.pct)
+			current = s.at(shown)
+			if shown != s.pct && glide == nil {
+				glide = time.After(smoothInterval)
+			}
+		}
+
+		p.mtx.Lock()
+		switch {
+		case s.state == finished:
+			p.unpin()
+			if c := p.compositor(); c != nil {
+				// the result scrolls above the block like a printed line
+				p.clearFrame()
 				p.mtx.Unlock()
+				c.printAbove(func() {
+					p.mtx.Lock()
+					defer p.mtx.Unlock()
+					p.drawFinal(s, frame, drawn)
+				})
+				return
 			}
+			p.drawFinal(s, frame, drawn)
+			p.mtx.Unlock()
 			return
+		case !paused && p.pinnedRow > 0:
+			// the cursor is returned to the scroll region after drawing
+			fmt.Fprintf(p.output, "\x1b7\x1b[%d;1H\x1b[2K", p.pinnedRow)
+			p.drawFrame(current, frame, drawn)
+			fmt.Fprint(p.output, "\x1b8")
+			drawn = s
+		case !paused:
+			p.drawFrame(current, frame, drawn)
+			drawn = s
+		}
+		p.mtx.Unlock()
+
+		select {
+		case <-glide:
+			glide = nil
+		case s = <-c:
+		case <-tick:
+			frame++
+			tick = time.After(p.frameInterval(def))
+			// don't draw a stale frame if the state changed while waiting
+			select {
+			case s = <-c:
+			default:
+			}
+		}
+	}
+}
+
+// smoothInterval is the time between frames while a bar glides to a new value
+const smoothInterval = 30 * time.Millisecond
+
+// approach moves a bar showing shown a step closer to target.  Bars only glide
+// forward and jump straight to a lower value.
+func approach(shown float64, target float64) float64 {
+	if target <= shown || target-shown < 0.005 {
+		return target
+	}
+	return shown + math.Max((target-shown)/4, 0.005)
+}
+
+// at returns s drawn at pct complete, with the count scaled to match
+func (s frameState) at(pct float64) frameState {
+	if s.total > 0 && pct != s.pct {
+		s.current = int64(pct * float64(s.total))
+	}
+	s.pct = pct
+	return s
+}
+
+// handle carries out the request in s, if any, and acknowledges it
+func (p *Progress) handle(s *frameState, paused *bool) {
+	if s.ack == nil {
+		return
+	}
+	switch s.request {
+	case pauseRequest:
+		*paused = true
+		if !p.json {
+			p.pause()
+		}
+	case resumeRequest:
+		*paused = false
+	case printRequest:
+		p.mtx.Lock()
+		switch {
+		case p.json:
+			writeJSONEvent(p.output, jsonEvent{Event: "log", Prompt: s.prompt, Message: s.text, Time: time.Now()})
 		default:
-			p.mtx.Lock()
-			fmt.Fprintf(p.output, "\x1b[?25l\r%s[%s]", p.Prompt, spinLookup(i, p.spinsteps))
-			p.mtx.Unlock()
-			time.Sleep(time.Duration(100) * time.Millisecond)
+			p.printAbove(s.text)
 		}
+		p.mtx.Unlock()
 	}
+	close(s.ack)
+	s.ack = nil
 }
 
-func renderLoading(p *Progress, c chan int) {
-	defer p.wg.Done()
-	if p.output == nil {
-		p.output = os.Stdout
+// pin reserves the bottom row of a terminal of height rows for the indicator by
+// scrolling everything up a line and limiting scrolling to the rows above it.  Must
+// be called with the mutex held.
+func (p *Progress) pin(height int) {
+	fmt.Fprintf(p.output, "\n\x1b7\x1b[1;%dr\x1b8\x1b[1A", height-1)
+	p.pinnedRow = height
+	if isTerminal(p.output) {
+		setScrollRegion(true)
 	}
+}
 
-	// delay to prevent flickering
-	// calling Success or Failure within delay will shortcircuit the loading indicator
-	if p.delay > 0 {
-		t := time.NewTicker(p.delay)
-		select {
-		case <-c:
-			return
-		case <-t.C:
-			t.Stop()
+// unpin clears the bottom row and lets the whole terminal scroll again.  The final
+// line of the indicator is then drawn in place at the cursor.  Must be called with
+// the mutex held.
+func (p *Progress) unpin() {
+	if p.pinnedRow == 0 {
+		return
+	}
+	fmt.Fprintf(p.output, "\x1b7\x1b[%d;1H\x1b[2K\x1b[r\x1b8", p.pinnedRow)
+	p.pinnedRow = 0
+	if isTerminal(p.output) {
+		setScrollRegion(false)
+	}
+}
+
+// printAbove clears the indicator, including any subtasks, and prints text in its
+// place.  The indicator is redrawn below the text on the next frame.  Must be called
+// with the mutex held.
+func (p *Progress) printAbove(text string) {
+	if p.pinnedRow > 0 {
+		// a pinned indicator is already out of the way
+		fmt.Fprintf(p.output, "%s\n", text)
+		return
+	}
+	fmt.Fprintf(p.output, "%s%s\n", eraseFrame(p.lines), text)
+	p.lines = 0
+}
+
+// drawFrame draws a running indicator.  A line is cleared before it is redrawn with a
+// different prompt or status so that a shorter one doesn't leave behind the end of
+// the old one.  Must be called with the mutex held.
+func (p *Progress) drawFrame(s frameState, frame int, drawn frameState) {
+	if len(drawn.prompt) > 0 && (drawn.prompt != s.prompt || drawn.status != s.status) && !p.multiline() {
+		fmt.Fprintf(p.output, "\r\x1b[2K")
+	}
+	if p.compact() {
+		// percentages can get shorter when a bar moves back
+		p.draw("\x1b[2K"+p.compactLine(s, frame), frame)
+		return
+	}
+	switch p.style {
+	case spinner:
+		step := spinLookup(frame, p.spinsteps)
+		suffix := s.tally() + s.statusSuffix() + p.suffix()
+		line := fmt.Sprintf("%s[%s]%s", p.stylePrompt(p.scrollPrompt(s.prompt, displayWidth(step)+2+displayWidth(suffix), frame)), p.styleSpinner(step), suffix)
+		if len(p.children) > 0 {
+			done, total := p.childCounts()
+			line = fmt.Sprintf("%s (%s of %s)", line, FormatNumber(int64(done)), FormatNumber(int64(total)))
 		}
+		p.draw(line, frame)
+	case loading:
+		step := spinLookup(frame, p.spinsteps)
+		suffix := p.suffix()
+		fmt.Fprintf(p.output, "\x1b[?25l\r%s  %s%s", p.styleSpinner(step), p.stylePrompt(p.scrollPrompt(s.prompt, displayWidth(step)+2+displayWidth(suffix), frame)), suffix)
+	case bar:
+		if len(p.children) > 0 {
+			s.pct = p.aggregate()
+		}
+		p.draw(p.barLine(s), frame)
 	}
+}
 
-	for i := 0; ; i++ {
-		select {
-		case <-c:
-			p.mtx.Lock()
-			fmt.Fprintf(p.output, "\x1b[?25l\r%s\r\n", strings.Repeat(" ", len(p.spinsteps[0])+len(p.Prompt)+3))
-			p.mtx.Unlock()
-			return
+// drawFinal draws the result of a finished indicator and restores the cursor.  Must
+// be called with the mutex held.
+func (p *Progress) drawFinal(s frameState, frame int, drawn frameState) {
+	if p.compact() {
+		// the result may be shorter than the percentage it replaces
+		fmt.Fprintf(p.output, "\x1b[?25h\r\x1b[2K%s\n", p.compactLine(s, frame))
+		return
+	}
+	switch p.style {
+	case spinner:
+		msg, sty := s.outcome()
+		suffix := s.tally() + p.suffix()
+		line := fmt.Sprintf("%s[%s]%s", p.stylePrompt(p.fitPrompt(s.prompt, displayWidth(msg)+2+displayWidth(suffix))), sty.ApplyTo(msg), suffix)
+		switch {
+		case p.multiline():
+			fmt.Fprintf(p.output, "\x1b[?25h%s\n", p.tree(line, frame))
+		case len(drawn.status) > 0:
+			// the status is longer than the result that replaces it
+			fmt.Fprintf(p.output, "\x1b[?25h\r\x1b[2K%s\n", line)
 		default:
-			p.mtx.Lock()
-			fmt.Fprintf(p.output, "\x1b[?25l\r%s  %s", spinLookup(i, p.spinsteps), p.Prompt)
-			p.mtx.Unlock()
-			time.Sleep(time.Duration(250) * time.Millisecond)
+			fmt.Fprintf(p.output, "\x1b[?25h\r%s\n", line)
 		}
+	case loading:
+		// loading only has one termination state
+		fmt.Fprintf(p.output, "\x1b[?25l\r%s\r\n", strings.Repeat(" ", displayWidth(p.spinsteps[0])+maxWidth(s.prompt, drawn.prompt)+displayWidth(p.suffix())+3))
+	case bar:
+		p.draw(p.barLine(s), frame)
+		fmt.Fprintf(p.output, "\x1b[?25h\n")
 	}
 }
 
+// suffix returns the time since the indicator started for WithElapsedTime and the
+// resource usage for WithResourceUsage, or an empty string.  Must be called with the
+// mutex held.
+func (p *Progress) suffix() string {
+	if p.started.IsZero() {
+		return ""
+	}
+	var suffix string
+	if p.elapsed {
+		end := time.Now()
+		if !p.stopped.IsZero() {
+			end = p.stopped
+		}
+		suffix = " " + formatElapsed(end.Sub(p.started))
+	}
+	if p.usage != nil {
+		if u, ok := p.usage.sample(); ok {
+			suffix += " " + u.String()
+		}
+	}
+	return suffix
+}
+
+// formatElapsed formats d as mm:ss, or h:mm:ss from an hour
+func formatElapsed(d time.Duration) string {
+	secs := int64(d / time.Second)
+	if secs >= 3600 {
+		return fmt.Sprintf("%d:%02d:%02d", secs/3600, secs/60%60, secs%60)
+	}
+	return fmt.Sprintf("%02d:%02d", secs/60, secs%60)
+}
+
+// styleSpinner applies SpinnerStyle to a spinner glyph
+func (p *Progress) styleSpinner(step string) string {
+	if p.SpinnerStyle == nil {
+		return step
+	}
+	return p.SpinnerStyle.ApplyTo(step)
+}
+
+// stylePrompt applies PromptStyle, or the prompt style of the theme, to a prompt that
+// has already been fit to the line
+func (p *Progress) stylePrompt(prompt string) string {
+	if p.PromptStyle == nil {
+		return CurrentTheme().Prompt.ApplyTo(prompt)
+	}
+	return p.PromptStyle.ApplyTo(prompt)
+}
+
+// draw writes line, followed by the lines of any subtasks.  Must be called with the
+// mutex held.
+func (p *Progress) draw(line string, frame int) {
+	switch {
+	case p.multiline():
+		fmt.Fprintf(p.output, "\x1b[?25l%s", p.tree(line, frame))
+	default:
+		fmt.Fprintf(p.output, "\x1b[?25l\r%s", line)
+	}
+}
+
+// maxWidth returns the display width of the wider of a and b
+func maxWidth(a string, b string) int {
+	if displayWidth(b) > displayWidth(a) {
+		return displayWidth(b)
+	}
+	return displayWidth(a)
+}
+
+const barDecorations = 9
+
+// displayLength returns the length of the display for the current render.  With
+// AutoSize, the display fills the terminal after accounting for the prompt and
+// the decorations around the display, leaving the last column empty so that the
+// terminal never wraps the line.
+func (p *Progress) displayLength(promptLen int, decorations int) int {
+	if !p.AutoSize {
+		return p.DisplayLength
+	}
+	width := p.width()
+	if width == 0 {
+		return p.DisplayLength
+	}
+	length := width - promptLen - decorations - 1
+	if length < 1 {
+		length = 1
+	}
+	return length
+}
+
+// minBarLength is the shortest bar shown when the prompt is truncated to fit
+const minBarLength = 10
+
+// fitPrompt returns the prompt to render when reserved columns of the line are needed
+// for the indicator.  Unless the policy is OverflowWrap, a prompt that doesn't fit in the
+// terminal is shortened with an ellipsis.  Must be called with the mutex held.
+func (p *Progress) fitPrompt(prompt string, reserved int) string {
+	if p.Overflow == OverflowWrap {
+		return prompt
+	}
+	width := p.width()
+	if width == 0 {
+		return prompt
+	}
+	return truncate(prompt, width-reserved-1)
+}
+
+// marqueeGap separates the end of a scrolling prompt from its beginning
+const marqueeGap = "   "
+
+// scrollPrompt is like fitPrompt, but with OverflowMarquee a prompt that doesn't fit
+// is scrolled one character for each animation frame.  Must be called with the mutex held.
+func (p *Progress) scrollPrompt(prompt string, reserved int, frame int) string {
+	if p.Overflow != OverflowMarquee {
+		return p.fitPrompt(prompt, reserved)
+	}
+	width := p.width()
+	if width == 0 {
+		return prompt
+	}
+	return marquee(prompt, width-reserved-1, frame)
+}
+
+// marquee returns a window of s that is n columns wide and starts frame characters
+// in, wrapping around to the beginning of s after a short gap
+func marquee(s string, n int, frame int) string {
+	switch {
+	case displayWidth(s) <= n:
+		return s
+	case n <= 0:
+		return ""
+	}
+	loop := append([]rune(s), []rune(marqueeGap)...)
+	start := frame % len(loop)
+	var window []rune
+	for i, w := 0, 0; i < len(loop); i++ {
+		r := loop[(start+i)%len(loop)]
+		if w+runeWidth(r) > n {
+			break
+		}
+		w += runeWidth(r)
+		window = append(window, r)
+	}
+	return string(window)
+}
+
+// truncate shortens s to at most n columns, ending with an ellipsis when
+// characters are removed
+func truncate(s string, n int) string {
+	switch {
+	case displayWidth(s) <= n:
+		return s
+	case n <= 0:
+		return ""
+	}
+	var out []rune
+	w := 0
+	for _, r := range s {
+		// leave a column for the ellipsis
+		if w+runeWidth(r) > n-1 {
+			break
+		}
+		w += runeWidth(r)
+		out = append(out, r)
+	}
+	return string(out) + "…"
+}
+
+// interval returns the time between animation frames, using def when
+// Interval hasn't been set
+func (p *Progress) interval(def time.Duration) time.Duration {
+	if p.Interval <= 0 {
+		return def
+	}
+	return p.Interval
+}
+
+// frameInterval returns the time until the next animation frame, which is longer for
+// low priority indicators while the display is busy
+func (p *Progress) frameInterval(def time.Duration) time.Duration {
+	d := p.interval(def)
+	if p.priority >= PriorityNormal || runningCount() < busyIndicators {
+		return d
+	}
+	return 4 * d
+}
+
 func spinLookup(i int, steps []string) string {
 	return steps[i%len(steps)]
 }
 
-func renderBar(p *Progress, c chan float64) {
-	defer p.wg.Done()
-	if p.output == nil {
-		p.output = os.Stdout
-	}
+// running tracks the progress indicators that have been started but not finished
+var runningSet = struct {
+	sync.Mutex
+	p []*Progress
+}{}
 
-	for result := range c {
-		eqLen := int(result * float64(p.DisplayLength))
-		spLen := p.DisplayLength - eqLen
-		switch {
-		case result == -1.0:
-			p.mtx.Lock()
-			fmt.Fprintf(p.output, "\x1b[?25l\r%s: [%s] %s", p.Prompt, strings.Repeat("=", p.DisplayLength), Styled(Green).ApplyTo("100%"))
-			p.mtx.Unlock()
-			fmt.Fprintf(p.output, "\x1b[?25h\n")
+func addRunning(p *Progress) {
+	runningSet.Lock()
+	defer runningSet.Unlock()
+	runningSet.p = append(runningSet.p, p)
+}
+
+func removeRunning(p *Progress) {
+	runningSet.Lock()
+	defer runningSet.Unlock()
+	for i, r := range runningSet.p {
+		if r == p {
+			runningSet.p = append(runningSet.p[:i], runningSet.p[i+1:]...)
 			return
-		case result == -2.0:
-			p.mtx.Lock()
-			fmt.Fprintf(p.output, "\x1b[?25l\r%s: [%s] %s", p.Prompt, strings.Repeat("X", p.DisplayLength), Styled(Red).ApplyTo("FAIL"))
-			p.mtx.Unlock()
-			fmt.Fprintf(p.output, "\x1b[?25h\n")
+		}
+	}
+}
+
+// runningCount returns the number of progress indicators that are currently running
+func runningCount() int {
+	runningSet.Lock()
+	defer runningSet.Unlock()
+	return len(runningSet.p)
+}
+
+// runningProgress returns the progress indicators that are currently running in
+// the order they were started
+func runningProgress() []*Progress {
+	runningSet.Lock()
+	defer runningSet.Unlock()
+	return append([]*Progress(nil), runningSet.p...)
+}
+
+// Pause temporarily stops rendering the progress indicator, clears its line and restores
+// the cursor so that the caller can print output or ask a question.  Call Resume to
+// continue the animation on the current line.  Subtasks are paused with their parent.
+func (p *Progress) Pause() {
+	p.setPaused(true)
+}
+
+// pause clears the line and restores the cursor
+func (p *Progress) pause() {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	if p.pinnedRow > 0 {
+		// a pinned indicator stays on its row while the cursor is used above it
+		fmt.Fprint(p.output, "\x1b[?25h")
+		return
+	}
+	p.clearFrame()
+	fmt.Fprint(p.output, "\x1b[?25h")
+}
+
+// clearFrame clears the lines of the indicator, leaving the cursor at the start of
+// the first one.  Must be called with the mutex held.
+func (p *Progress) clearFrame() {
+	if p.lines > 1 {
+		fmt.Fprint(p.output, eraseFrame(p.lines))
+	} else {
+		fmt.Fprint(p.output, "\r\x1b[2K")
+	}
+	p.lines = 0
+}
+
+// Resume continues rendering a paused progress indicator
+func (p *Progress) Resume() {
+	p.setPaused(false)
+}
+
+// setPaused asks the render goroutine to pause or resume and waits until it has.
+// Snapshots are queued, so the ones already sent are drawn before pausing.
+func (p *Progress) setPaused(paused bool) {
+	p.mtx.Lock()
+	if p.state != running || p.paused == paused || p.parent != nil {
+		p.mtx.Unlock()
+		return
+	}
+	p.paused = paused
+	request := resumeRequest
+	if paused {
+		request = pauseRequest
+	}
+	p.request(request, "")
+}
+
+// request sends a request to the render goroutine and waits until it has been
+// handled.  Must be called with the mutex held, and returns with it released.
+func (p *Progress) request(request int, text string) {
+	p.inflight.Add(1)
+	s := p.frameState()
+	s.ack, s.request, s.text = make(chan struct{}), request, text
+	p.mtx.Unlock()
+	p.c <- s
+	p.inflight.Done()
+	<-s.ack
+}
+
+// Println prints a line of output above the progress indicator and redraws the
+// indicator below it, so that log messages can be interleaved with a running spinner
+// or bar without corrupting its line.  Output from subtasks is printed above their
+// parent.  If the indicator isn't running, the line is printed as usual.
+func (p *Progress) Println(format string, args ...interface{}) {
+	root := p
+	for root.parent != nil {
+		root = root.parent
+	}
+	text := fmt.Sprintf(format, args...)
+	root.mtx.Lock()
+	if root.state != running {
+		fmt.Fprintf(root.output, "%s\n", text)
+		root.mtx.Unlock()
+		return
+	}
+	if c := root.compositor(); c != nil {
+		// the line goes above the block that the indicator is drawn below
+		root.mtx.Unlock()
+		c.Println("%s", text)
+		return
+	}
+	root.request(printRequest, text)
+}
+
+// Println prints a line of output above the most recently started progress indicator
+// that is still running, or above the block of a compositor on the terminal, or to
+// os.Stdout if there is neither
+func Println(format string, args ...interface{}) {
+	if running := runningProgress(); len(running) > 0 {
+		running[len(running)-1].Println(format, args...)
+		return
+	}
+	active := activeCompositors()
+	for i := len(active) - 1; i >= 0; i-- {
+		if active[i].live {
+			active[i].Println(format, args...)
 			return
-		case result >= 0.0:
-			p.mtx.Lock()
-			fmt.Fprintf(p.output, "\x1b[?25l\r%s: [%s%s] %2.0f%%", p.Prompt, strings.Repeat("=", eqLen), strings.Repeat(" ", spLen), 100.0*result)
-			p.mtx.Unlock()
 		}
+	}
+	fmt.Fprintf(os.Stdout, "%s\n", fmt.Sprintf(format, args...))
+}
+
+// barLine returns the rendered bar for s, sized to fit the terminal.  Must be called
+// with the mutex held.
+func (p *Progress) barLine(s frameState) string {
+	counts := s.countSuffix() + p.suffix()
+	prompt := p.fitPrompt(s.prompt, barDecorations+len(counts)+minBarLength)
+	length := p.displayLength(displayWidth(prompt), barDecorations+len(counts))
+	switch {
+	case s.state == finished && s.result == success:
+		return fmt.Sprintf("%s: [%s] %s%s", prompt, p.body(length, 8*length, 1.0), CurrentTheme().Success.ApplyTo(s.trailer("100%")), counts)
+	case s.state == finished && s.result == custom:
+		// the bar is left where it stopped
+		msg, sty := s.outcome()
+		return fmt.Sprintf("%s: [%s] %s%s", prompt, p.body(length, s.eighths(length), s.pct), sty.ApplyTo(msg), counts)
+	case s.state == finished:
+		return fmt.Sprintf("%s: [%s] %s%s", prompt, strings.Repeat("X", length), CurrentTheme().Error.ApplyTo(s.trailer("FAIL")), counts)
+	case s.total > 0:
+		return fmt.Sprintf("%s: [%s] %2d%%%s", prompt, p.body(length, s.eighths(length), s.pct), scale(s.current, s.total, 100), counts)
+	}
+	return fmt.Sprintf("%s: [%s] %2.0f%%%s", prompt, p.body(length, s.eighths(length), s.pct), 100.0*s.pct, counts)
+}
+
+// eighths returns how many eighths of a column are filled in a bar of length columns
+func (s frameState) eighths(length int) int {
+	if s.total > 0 {
+		// exact integer math so very large totals don't suffer from float rounding
+		return scale(s.current, s.total, 8*length)
+	}
+	return int(s.pct * float64(8*length))
+}
+
+// partialBlocks are the glyphs for a column that is one to seven eighths filled
+var partialBlocks = []string{"", "▏", "▎", "▍", "▌", "▋", "▊", "▉"}
+
+// body returns the inside of a bar of length columns with eighths filled and the rest
+// padded with spaces
+func (p *Progress) body(length int, eighths int, pct float64) string {
+	if eighths < 0 {
+		eighths = 0
+	}
+	if !p.blocks {
+		n := eighths / 8
+		return p.fill(n, pct) + strings.Repeat(" ", nonNegative(length-n))
+	}
+	full, rem := eighths/8, eighths%8
+	fill := strings.Repeat("█", full) + partialBlocks[rem]
+	pad := length - full
+	if rem > 0 {
+		pad--
+	}
+	pad = nonNegative(pad)
+	if p.BarColor != nil && len(fill) > 0 {
+		fill = p.BarColor(pct).ApplyTo(fill)
+	}
+	return fill + strings.Repeat(" ", pad)
+}
 
+// fill returns the filled portion of the bar, colored by BarColor for pct
+func (p *Progress) fill(n int, pct float64) string {
+	n = nonNegative(n)
+	fill := strings.Repeat("=", n)
+	if p.BarColor == nil || n == 0 {
+		return fill
 	}
+	return p.BarColor(pct).ApplyTo(fill)
+}
+
+// nonNegative returns n, or 0 if it is negative, for counts passed to strings.Repeat
+func nonNegative(n int) int {
+	if n < 0 {
+		return 0
+	}
+	return n
+}
+
+// ThresholdColors colors a bar red below 33%, yellow below 66% and green above that,
+// or with the error, warning and success styles of the theme set with SetTheme.
+// Use it as the BarColor of a progress bar for an at-a-glance view of how far along
+// a long operation is.
+func ThresholdColors(pct float64) *Style {
+	switch {
+	case pct < 0.33:
+		return CurrentTheme().Error
+	case pct < 0.66:
+		return CurrentTheme().Warning
+	}
+	return CurrentTheme().Success
+}
+
+// tally returns the count shown after the spinner of a counter, such as 12403 found,
+// or an empty string for other indicators.  Counts of bytes are shown in human units.
+func (s frameState) tally() string {
+	if !s.counting {
+		return ""
+	}
+	count := FormatNumber(s.current)
+	if s.bytes {
+		count = Bytes(s.current)
+	}
+	if len(s.unit) == 0 {
+		return " " + count
+	}
+	return fmt.Sprintf(" %s %s", count, s.unit)
+}
+
+// statusSuffix returns the status set with SetStatus, separated from the spinner
+func (s frameState) statusSuffix() string {
+	if len(s.status) == 0 {
+		return ""
+	}
+	return " " + s.status
+}
+
+// countSuffix returns the " (N of M)" display for bars with a total set, or an
+// empty string for bars updated with percentages.  Byte totals are shown in human
+// units, followed by the detail if there is one.  A successful bar always shows the
+// full total.
+func (s frameState) countSuffix() string {
+	if s.total <= 0 {
+		return ""
+	}
+	current := s.current
+	if s.state == finished && s.result == success {
+		current = s.total
+	}
+	detail := ""
+	if len(s.detail) > 0 {
+		detail = ", " + s.detail
+	}
+	if s.bytes {
+		return fmt.Sprintf(" (%s of %s%s)", Bytes(current), Bytes(s.total), detail)
+	}
+	return fmt.Sprintf(" (%s of %s%s)", FormatNumber(current), FormatNumber(s.total), detail)
+}
+
+// scale returns n/total*width without overflowing or losing precision for totals
+// up to the full int64 range.  n is clamped to [0, total].
+func scale(n int64, total int64, width int) int {
+	switch {
+	case total <= 0 || n <= 0 || width <= 0:
+		return 0
+	case n >= total:
+		return width
+	}
+	hi, lo := bits.Mul64(uint64(n), uint64(width))
+	q, _ := bits.Div64(hi, lo, uint64(total))
+	return int(q)
+}
+
+// SetTotal sets the total number of items (e.g., files or bytes) that a bar is counting
+// toward.  Once set, use UpdateCount or Add to report progress and the bar will show
+// exact "N of M" counts alongside the percentage.
+func (p *Progress) SetTotal(total int64) {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	p.total = total
+	p.bytes = false
+}
+
+// SetTotalBytes sets the total number of bytes to transfer.  Counts are shown in
+// human units, such as (1.2 MB of 4.5 GB).
+func (p *Progress) SetTotalBytes(total int64) {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	p.total = total
+	p.bytes = true
+}
+
+// UpdateCount sets the number of items completed out of the total set with SetTotal,
+// or the number counted so far by a counter
+func (p *Progress) UpdateCount(current int64) {
+	p.mtx.Lock()
+	p.setCount(current)
+	draw := p.countChanged()
+	p.mtx.Unlock()
+	if draw {
+		p.update(nil)
+	}
+}
+
+// Add increments the number of items completed by n.  It is safe to call from
+// multiple goroutines.
+func (p *Progress) Add(n int64) {
+	p.mtx.Lock()
+	p.setCount(p.current + n)
+	draw := p.countChanged()
+	p.mtx.Unlock()
+	if draw {
+		p.update(nil)
+	}
+}
+
+// countChanged returns true if a new count should be drawn.  Counters are often
+// incremented for every item, so they are only redrawn once per Interval.  Must be
+// called with the mutex held.
+func (p *Progress) countChanged() bool {
+	switch {
+	case p.style == bar:
+		return true
+	case !p.counting || time.Since(p.tallied) < p.Interval:
+		return false
+	}
+	p.tallied = time.Now()
+	return true
+}
+
+// Increment adds one to the count of a counter or bar
+func (p *Progress) Increment() {
+	p.Add(1)
+}
+
+// setCount stores the completed count and the matching pct.  Counters have no
+// total, so only the count is stored.  Must be called with the mutex held.
+func (p *Progress) setCount(current int64) {
+	if p.counting {
+		p.current = current
+		return
+	}
+	if current > p.total {
+		current = p.total
+	}
+	p.current = current
+	p.pct = float64(scale(current, p.total, 1<<20)) / float64(1<<20)
 }
 
 // Update the progress bar using a number [0, 1.0] to represent
-// the percentage complete
+// the percentage complete.  Updates before Start, after the bar has finished,
+// or to spinners are ignored.
 func (p *Progress) Update(pct float64) {
-	p.wg.Add(1)
-	defer p.wg.Done()
-	if pct >= 1.0 {
+	switch {
+	case pct >= 1.0:
 		pct = 1.0
+	case pct < 0 || math.IsNaN(pct):
+		pct = 0
+	}
+	if p.style == bar {
+		p.update(func() { p.pct = pct })
 	}
-	p.cf <- pct
 }
@@ -2,6 +2,10 @@ package clt
 
 import (
 	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
 	"testing"
 	"time"
 
@@ -13,6 +17,7 @@ func TestProgressSpinner(t *testing.T) {
 
 	p := NewProgressSpinner("Testing a successful result")
 	p.output = out
+	p.Interval = 250 * time.Millisecond
 	p.Start()
 	time.Sleep(1 * time.Second)
 	p.Success()
@@ -40,3 +45,545 @@ func TestLoading(t *testing.T) {
 	p.Success()
 	snapshot.Assert(t, out.Bytes())
 }
+
+func TestProgressBarNegative(t *testing.T) {
+	out := bytes.NewBuffer(nil)
+
+	p := NewProgressBar("Testing a negative update")
+	p.output = out
+	p.Start()
+	p.Update(-0.5)
+	p.Fail()
+	empty := "[" + strings.Repeat(" ", p.DisplayLength) + "]  0%"
+	if !strings.Contains(out.String(), empty) {
+		t.Errorf("Expected a negative update to draw an empty bar %q, got %q", empty, out.String())
+	}
+}
+
+func TestProgressBarStartAt(t *testing.T) {
+	out := bytes.NewBuffer(nil)
+
+	p := NewProgressBar("Testing a resumed result")
+	p.output = out
+	p.StartAt(0.35)
+	p.Success()
+	snapshot.Assert(t, out.Bytes())
+}
+
+func TestAutoSizeFallback(t *testing.T) {
+	p := NewProgressBar("Not a terminal")
+	p.output = bytes.NewBuffer(nil)
+	if got := p.displayLength(len(p.Prompt), barDecorations); got != p.DisplayLength {
+		t.Errorf("Expected display length to fall back to %d when output is not a terminal, got %d", p.DisplayLength, got)
+	}
+}
+
+func TestProgressBarCounts(t *testing.T) {
+	out := bytes.NewBuffer(nil)
+
+	p := NewProgressBar("Testing a large total")
+	p.output = out
+	p.SetTotal(4 << 40)
+	p.Start()
+	p.UpdateCount(1 << 40)
+	p.Add(1 << 40)
+	p.Success()
+	snapshot.Assert(t, out.Bytes())
+}
+
+func TestScale(t *testing.T) {
+	tt := []struct {
+		n, total int64
+		width    int
+		want     int
+	}{
+		{n: 1, total: 3, width: 100, want: 33},
+		{n: 1<<62 - 1, total: 1<<62 + 1, width: 20, want: 19},
+		{n: 9000000000000000000, total: 9000000000000000000, width: 100, want: 100},
+		{n: -1, total: 10, width: 10, want: 0},
+	}
+	for _, tc := range tt {
+		if got := scale(tc.n, tc.total, tc.width); got != tc.want {
+			t.Errorf("scale(%d, %d, %d) expected %d, got %d", tc.n, tc.total, tc.width, tc.want, got)
+		}
+	}
+}
+
+func TestProgressCustomMessages(t *testing.T) {
+	out := bytes.NewBuffer(nil)
+
+	p := NewProgressSpinner("Testing a custom failure")
+	p.output = out
+	p.Interval = 250 * time.Millisecond
+	p.Start()
+	time.Sleep(100 * time.Millisecond)
+	p.FailWith("timeout after %ds", 3)
+
+	p2 := NewProgressBar("Testing a custom success")
+	p2.output = out
+	p2.Start()
+	p2.SuccessWith("done")
+	snapshot.Assert(t, out.Bytes())
+}
+
+func TestTruncate(t *testing.T) {
+	tt := []struct {
+		s    string
+		n    int
+		want string
+	}{
+		{s: "short", n: 10, want: "short"},
+		{s: "a long prompt", n: 6, want: "a lon…"},
+		{s: "héllo wörld", n: 5, want: "héll…"},
+		{s: "anything", n: 0, want: ""},
+	}
+	for _, tc := range tt {
+		if got := truncate(tc.s, tc.n); got != tc.want {
+			t.Errorf("truncate(%q, %d) expected %q, got %q", tc.s, tc.n, tc.want, got)
+		}
+	}
+}
+
+func TestProgressTerminationIsIdempotent(t *testing.T) {
+	out := bytes.NewBuffer(nil)
+
+	p := NewProgressBar("Testing repeated calls")
+	p.output = out
+	if err := p.Success(); err != ErrNotStarted {
+		t.Errorf("Expected ErrNotStarted before Start, got %v", err)
+	}
+	p.Update(0.5)
+	p.Start()
+	if err := p.Stop(nil); err != nil {
+		t.Errorf("Expected first Stop to succeed, got %v", err)
+	}
+	if err := p.Fail(); err != ErrFinished {
+		t.Errorf("Expected ErrFinished after finishing, got %v", err)
+	}
+	p.Update(0.75)
+	p.Start()
+
+	s := NewProgressSpinner("Testing stop with an error")
+	s.output = bytes.NewBuffer(nil)
+	s.Interval = time.Second
+	s.Start()
+	s.Stop(fmt.Errorf("failed"))
+	if err := s.Success(); err != ErrFinished {
+		t.Errorf("Expected ErrFinished after finishing, got %v", err)
+	}
+	snapshot.Assert(t, out.Bytes())
+}
+
+func TestMarquee(t *testing.T) {
+	tt := []struct {
+		frame int
+		want  string
+	}{
+		{frame: 0, want: "abcd"},
+		{frame: 3, want: "def "},
+		{frame: 6, want: "   a"},
+		{frame: 9, want: "abcd"},
+	}
+	for _, tc := range tt {
+		if got := marquee("abcdef", 4, tc.frame); got != tc.want {
+			t.Errorf("marquee frame %d expected %q, got %q", tc.frame, tc.want, got)
+		}
+	}
+	if got := marquee("fits", 4, 2); got != "fits" {
+		t.Errorf("marquee should not scroll text that fits, got %q", got)
+	}
+}
+
+func TestProgressPauseResume(t *testing.T) {
+	out := bytes.NewBuffer(nil)
+
+	p := NewProgressBar("Testing pause")
+	p.output = out
+	p.Start()
+	p.Update(0.25)
+	p.Pause()
+	p.Update(0.5)
+	out.WriteString("Continue? y\n")
+	p.Resume()
+	p.Success()
+	snapshot.Assert(t, out.Bytes())
+}
+
+func TestProgressPrintln(t *testing.T) {
+	out := bytes.NewBuffer(nil)
+
+	p := NewProgressBar("Installing")
+	p.output = out
+	p.Println("before start")
+	p.Start()
+	p.Update(0.25)
+	p.Println("installed %s", "foo")
+	p.Update(0.5)
+	Println("installed %s", "bar")
+	p.Success()
+	p.Println("after finish")
+	snapshot.Assert(t, out.Bytes())
+}
+
+func TestProgressUpdatePrompt(t *testing.T) {
+	out := bytes.NewBuffer(nil)
+
+	p := NewProgressBar("Downloading a very long file name")
+	p.output = out
+	p.Start()
+	p.Update(0.5)
+	p.UpdatePrompt("Verifying")
+	p.Success()
+	snapshot.Assert(t, out.Bytes())
+
+	// prompt changes while a spinner is running are picked up by the render goroutine
+	s := NewProgressSpinner("Connecting")
+	s.output = bytes.NewBuffer(nil)
+	s.Interval = time.Millisecond
+	s.Start()
+	for i := 0; i < 10; i++ {
+		s.UpdatePrompt(fmt.Sprintf("Connecting (attempt %d)", i))
+		time.Sleep(time.Millisecond)
+	}
+	s.Success()
+}
+
+func TestProgressOptions(t *testing.T) {
+	out := bytes.NewBuffer(nil)
+
+	p := NewProgressBar("Copying %s", "a.txt", WithProgressOutput(out), WithLength(10))
+	if p.Prompt != "Copying a.txt" {
+		t.Errorf("Expected options to be removed from the format args, got %q", p.Prompt)
+	}
+	p.Start()
+	p.Update(0.5)
+	p.Success()
+	if want := "Copying a.txt: [=====     ] 50%"; !strings.Contains(out.String(), want) {
+		t.Errorf("Expected output to contain %q, got %q", want, out.String())
+	}
+
+	s := NewProgressSpinner("Waiting", WithSpinner(Dots))
+	if s.spinsteps[0] != Dots[0] {
+		t.Errorf("Expected the Dots spinner, got %v", s.spinsteps)
+	}
+
+	tty := NewProgressBar("Wide", WithWriterIsTTY(true))
+	tty.output = bytes.NewBuffer(nil)
+	if got := tty.displayLength(len("Wide"), barDecorations); got != 80-len("Wide")-barDecorations-1 {
+		t.Errorf("Expected an 80 column display, got %d", got)
+	}
+	notty := NewProgressBar("Narrow", WithWriterIsTTY(false))
+	if got := notty.displayLength(len("Narrow"), barDecorations); got != notty.DisplayLength {
+		t.Errorf("Expected the default display length, got %d", got)
+	}
+}
+
+func TestProgressSetOutput(t *testing.T) {
+	out := bytes.NewBuffer(nil)
+	p := NewProgressBar("Parent")
+	c := p.Child("child")
+	p.SetOutput(out)
+	if c.output != out {
+		t.Errorf("Expected SetOutput to apply to subtasks")
+	}
+}
+
+func TestProgressSpinnerStyle(t *testing.T) {
+	out := bytes.NewBuffer(nil)
+
+	p := NewProgressSpinner("Styled")
+	p.output = out
+	p.Interval = time.Hour
+	p.SpinnerStyle = Styled(Cyan)
+	p.PromptStyle = Styled(Bold)
+	p.Start()
+	p.Success()
+	snapshot.Assert(t, out.Bytes())
+}
+
+func TestProgressBarColor(t *testing.T) {
+	out := bytes.NewBuffer(nil)
+
+	p := NewProgressBar("Migrating")
+	p.output = out
+	p.BarColor = ThresholdColors
+	p.Start()
+	p.Update(0.25)
+	p.Update(0.5)
+	p.Update(0.75)
+	p.Success()
+	snapshot.Assert(t, out.Bytes())
+}
+
+func TestProgressBarBytes(t *testing.T) {
+	p := NewProgressBar("Downloading")
+	p.SetTotalBytes(4500000000)
+	p.setCount(1200000)
+	if got, want := p.frameState().countSuffix(), " (1.2 MB of 4.5 GB)"; got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+}
+
+func TestProgressElapsedTime(t *testing.T) {
+	if got := formatElapsed(83 * time.Second); got != "01:23" {
+		t.Errorf("Expected 01:23, got %s", got)
+	}
+	if got := formatElapsed(time.Hour + 2*time.Minute + 3*time.Second); got != "1:02:03" {
+		t.Errorf("Expected 1:02:03, got %s", got)
+	}
+
+	out := bytes.NewBuffer(nil)
+	p := NewProgressSpinner("Building", WithProgressOutput(out), WithElapsedTime())
+	p.Interval = time.Millisecond
+	p.Start()
+	time.Sleep(5 * time.Millisecond)
+	p.Success()
+	if !strings.Contains(out.String(), "OK\x1b[39m] 00:00\n") {
+		t.Errorf("Expected the elapsed time after the result, got %q", out.String())
+	}
+}
+
+func TestProgressFinish(t *testing.T) {
+	out := bytes.NewBuffer(nil)
+
+	p := NewProgressSpinner("Linting")
+	p.output = out
+	p.Interval = time.Second
+	p.Start()
+	p.Finish("WARN", Styled(Yellow))
+	if err := p.Success(); err != ErrFinished {
+		t.Errorf("Expected ErrFinished after Finish, got %v", err)
+	}
+
+	b := NewProgressBar("Uploading")
+	b.output = out
+	b.Start()
+	b.Update(0.5)
+	b.Finish("SKIPPED", Styled(Dim))
+	snapshot.Assert(t, out.Bytes())
+}
+
+func TestProgressResourceUsage(t *testing.T) {
+	if got, want := (resourceUsage{cpu: 12.4, rss: 48000000}).String(), "[CPU  12% RSS  48 MB]"; got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+
+	out := bytes.NewBuffer(nil)
+	p := NewProgressBar("Indexing", WithProgressOutput(out), WithResourceUsage())
+	p.Start()
+	p.Update(0.5)
+	p.Success()
+	if _, ok := processRSS(); ok && !strings.Contains(out.String(), "[CPU ") {
+		t.Errorf("Expected the resource usage on the bar, got %q", out.String())
+	}
+}
+
+func TestProgressSetStatus(t *testing.T) {
+	out := bytes.NewBuffer(nil)
+
+	p := NewProgressSpinner("Deploying...")
+	p.output = out
+	p.Interval = time.Hour
+	p.Start()
+	p.SetStatus("uploading layer 3/7")
+	p.SetStatus("pushing")
+	p.Success()
+	snapshot.Assert(t, out.Bytes())
+}
+
+func TestProgressBarSmoothing(t *testing.T) {
+	if got := approach(0.2, 0.1); got != 0.1 {
+		t.Errorf("Expected bars to jump back to a lower value, got %v", got)
+	}
+
+	out := bytes.NewBuffer(nil)
+	p := NewProgressBar("Copying", WithProgressOutput(out), WithWriterIsTTY(true), WithLength(20))
+	p.Start()
+	p.Update(0.8)
+	time.Sleep(500 * time.Millisecond)
+	p.Success()
+	if frames := strings.Count(out.String(), "\x1b[?25l\r"); frames < 5 {
+		t.Errorf("Expected the bar to glide over several frames, got %d frames in %q", frames, out.String())
+	}
+	if !strings.Contains(out.String(), "80%") {
+		t.Errorf("Expected the bar to reach the updated value, got %q", out.String())
+	}
+
+	// other output shows the exact values
+	out.Reset()
+	p = NewProgressBar("Copying", WithProgressOutput(out))
+	p.Start()
+	p.Update(0.8)
+	p.Success()
+	if frames := strings.Count(out.String(), "\x1b[?25l\r"); frames != 3 {
+		t.Errorf("Expected no smoothing when the output isn't a terminal, got %d frames", frames)
+	}
+}
+
+func TestProgressPartialBlocks(t *testing.T) {
+	p := NewProgressBar("Copying", WithPartialBlocks())
+	if got, want := p.body(10, 42, 0.525), "█████▎    "; got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+	if got, want := p.body(10, 40, 0.5), "█████     "; got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+
+	out := bytes.NewBuffer(nil)
+	p = NewProgressBar("Copying", WithProgressOutput(out), WithPartialBlocks(), WithLength(20))
+	p.SetTotal(160)
+	p.Start()
+	p.UpdateCount(13)
+	p.Success()
+	if !strings.Contains(out.String(), "[█▋                  ]") {
+		t.Errorf("Expected the bar to advance by eighths of a column, got %q", out.String())
+	}
+}
+
+func TestProgressPinnedLine(t *testing.T) {
+	out := bytes.NewBuffer(nil)
+	p := NewProgressBar("Compiling", WithProgressOutput(out), WithWriterIsTTY(true), WithLength(10), WithSmoothing(false), WithPinnedLine())
+	p.Start()
+	p.Println("compiled %s", "lexer")
+	p.Update(0.5)
+	p.Success()
+
+	got := out.String()
+	for _, want := range []string{"\n\x1b7\x1b[1;23r\x1b8\x1b[1A", "\x1b7\x1b[24;1H\x1b[2K\x1b[?25l\rCompiling: [=====     ]", "\x1b7\x1b[24;1H\x1b[2K\x1b[r\x1b8"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Expected output to contain %q, got %q", want, got)
+		}
+	}
+	// output above a pinned bar doesn't clear the screen below the cursor
+	if !strings.Contains(got, "compiled lexer\n") || strings.Contains(got, "\x1b[J") {
+		t.Errorf("Expected printed lines to scroll above the bar, got %q", got)
+	}
+	if !strings.HasSuffix(got, "\x1b8\x1b[?25l\rCompiling: [==========] \x1b[32m100%\x1b[39m\x1b[?25h\n") {
+		t.Errorf("Expected the final bar to be drawn in place, got %q", got)
+	}
+}
+
+func TestProgressFailAfter(t *testing.T) {
+	out := bytes.NewBuffer(nil)
+	p := NewProgressSpinner("Connecting", WithProgressOutput(out))
+	p.Interval = time.Hour
+	p.Start()
+	p.FailAfter(20 * time.Millisecond)
+	time.Sleep(100 * time.Millisecond)
+	if err := p.Success(); err != ErrTimedOut {
+		t.Errorf("Expected ErrTimedOut after the watchdog fired, got %v", err)
+	}
+	if !strings.Contains(out.String(), "TIMEOUT after 20ms") || !strings.HasSuffix(out.String(), "\n") {
+		t.Errorf("Expected the spinner to fail with a timeout, got %q", out.String())
+	}
+
+	// finishing in time stops the watchdog
+	p = NewProgressSpinner("Connecting", WithProgressOutput(bytes.NewBuffer(nil)))
+	p.Start()
+	p.FailAfter(20 * time.Millisecond)
+	if err := p.Success(); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(50 * time.Millisecond)
+	if err := p.Fail(); err != ErrFinished {
+		t.Errorf("Expected the watchdog to be stopped, got %v", err)
+	}
+}
+
+func TestProgressCounter(t *testing.T) {
+	out := bytes.NewBuffer(nil)
+	defer SetLanguage("")
+	SetLanguage("en")
+
+	p := NewProgressCounter("Scanning files...", WithProgressOutput(out), WithCountUnit("found"))
+	p.Interval = time.Hour
+	p.Start()
+	p.Increment()
+	for i := 0; i < 12402; i++ {
+		p.Increment()
+	}
+	p.Success()
+	got := out.String()
+	if !strings.Contains(got, "Scanning files...[|] 1 found") {
+		t.Errorf("Expected the first count to be drawn, got %q", got)
+	}
+	if !strings.HasSuffix(got, "Scanning files...[\x1b[32mOK\x1b[39m] 12,403 found\n") {
+		t.Errorf("Expected the final count after the result, got %q", got)
+	}
+	if strings.Count(got, "found") > 3 {
+		t.Errorf("Expected increments to be drawn at most once per interval, got %q", got)
+	}
+}
+
+func TestProgressGo(t *testing.T) {
+	out := bytes.NewBuffer(nil)
+	boom := errors.New("boom")
+
+	p := NewProgressSpinner("Deploying", WithProgressOutput(out))
+	if err := p.Go(func(ctx context.Context) error { return boom }); err != boom {
+		t.Errorf("Expected the error from the work, got %v", err)
+	}
+	if !strings.Contains(out.String(), "FAIL") {
+		t.Errorf("Expected the spinner to fail, got %q", out.String())
+	}
+
+	// the watchdog cancels the work
+	p = NewProgressSpinner("Deploying", WithProgressOutput(bytes.NewBuffer(nil)))
+	p.FailAfter(10 * time.Millisecond)
+	err := p.Go(func(ctx context.Context) error {
+		<-ctx.Done()
+		return nil
+	})
+	if err != ErrTimedOut {
+		t.Errorf("Expected ErrTimedOut, got %v", err)
+	}
+
+	out.Reset()
+	p = NewProgressSpinner("Deploying", WithProgressOutput(out))
+	func() {
+		defer func() {
+			if r := recover(); r != boom {
+				t.Errorf("Expected the panic to continue, got %v", r)
+			}
+		}()
+		p.Go(func(ctx context.Context) error { panic(boom) })
+	}()
+	if !strings.HasSuffix(out.String(), "\x1b[?25h\rDeploying[\x1b[31mFAIL\x1b[39m]\n") {
+		t.Errorf("Expected a panic to fail the spinner and show the cursor, got %q", out.String())
+	}
+}
+
+func TestPriority(t *testing.T) {
+	low := NewProgressBar("Gauge", WithPriority(PriorityLow))
+	normal := NewProgressSpinner("Working")
+	high := NewProgressSpinner("Failing", WithPriority(PriorityHigh))
+	for _, p := range []*Progress{low, normal, high} {
+		p.Interval = 100 * time.Millisecond
+		if got := p.frameInterval(0); got != p.Interval {
+			t.Errorf("expected %s to draw every frame when the display isn't busy, got %s", p.Prompt, got)
+		}
+	}
+
+	others := make([]*Progress, busyIndicators)
+	for i := range others {
+		others[i] = NewProgressSpinner("Other")
+		addRunning(others[i])
+	}
+	defer func() {
+		for _, p := range others {
+			removeRunning(p)
+		}
+	}()
+	for _, tc := range []struct {
+		p    *Progress
+		want time.Duration
+	}{
+		{low, 400 * time.Millisecond},
+		{normal, 100 * time.Millisecond},
+		{high, 100 * time.Millisecond},
+	} {
+		if got := tc.p.frameInterval(0); got != tc.want {
+			t.Errorf("expected %s to draw every %s while busy, got %s", tc.p.Prompt, tc.want, got)
+		}
+	}
+}
@@ -0,0 +1,202 @@
+package clt
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Quantizer picks the color from palette that looks closest to c and returns its
+// index.  It is used to show RGB colors on terminals with only 256 or 16 colors.
+type Quantizer func(c RGBColor, palette []RGBColor) int
+
+// quantizer is the Quantizer set with SetQuantizer
+var quantizer = struct {
+	sync.Mutex
+	q Quantizer
+}{q: NearestCIEDE2000}
+
+// SetQuantizer sets how RGB colors are matched to the colors of terminals without
+// truecolor.  It defaults to NearestCIEDE2000.
+func SetQuantizer(q Quantizer) {
+	quantizer.Lock()
+	defer quantizer.Unlock()
+	if q == nil {
+		q = NearestCIEDE2000
+	}
+	quantizer.q = q
+}
+
+func currentQuantizer() Quantizer {
+	quantizer.Lock()
+	defer quantizer.Unlock()
+	return quantizer.q
+}
+
+// NearestRGB picks the palette color with the smallest straight-line distance to c
+// in RGB.  It is fast but treats differences in every channel as equally visible.
+func NearestRGB(c RGBColor, palette []RGBColor) int {
+	return nearest(palette, func(p RGBColor) float64 {
+		dr, dg, db := float64(c.R)-float64(p.R), float64(c.G)-float64(p.G), float64(c.B)-float64(p.B)
+		return dr*dr + dg*dg + db*db
+	})
+}
+
+// NearestCIEDE2000 picks the palette color that looks closest to c according to the
+// CIEDE2000 color difference, which follows how people perceive differences in
+// lightness, saturation and hue
+func NearestCIEDE2000(c RGBColor, palette []RGBColor) int {
+	lab := c.lab()
+	return nearest(palette, func(p RGBColor) float64 { return ciede2000(lab, p.lab()) })
+}
+
+// nearest returns the index of the palette color with the smallest distance
+func nearest(palette []RGBColor, distance func(p RGBColor) float64) int {
+	best, bestDist := 0, math.Inf(1)
+	for i, p := range palette {
+		if d := distance(p); d < bestDist {
+			best, bestDist = i, d
+		}
+	}
+	return best
+}
+
+// palette16 holds the xterm defaults for the basic colors and their bright versions
+var palette16 = []RGBColor{
+	{0, 0, 0}, {205, 0, 0}, {0, 205, 0}, {205, 205, 0}, {0, 0, 238}, {205, 0, 205}, {0, 205, 205}, {229, 229, 229},
+	{127, 127, 127}, {255, 0, 0}, {0, 255, 0}, {255, 255, 0}, {92, 92, 255}, {255, 0, 255}, {0, 255, 255}, {255, 255, 255},
+}
+
+// palette256 holds the 6x6x6 color cube and grayscale ramp that follow the basic
+// colors in the xterm palette.  The basic colors are left out because terminals
+// often change them.
+var palette256 = func() []RGBColor {
+	levels := []uint8{0, 95, 135, 175, 215, 255}
+	p := make([]RGBColor, 0, 240)
+	for _, r := range levels {
+		for _, g := range levels {
+			for _, b := range levels {
+				p = append(p, RGBColor{r, g, b})
+			}
+		}
+	}
+	for i := 0; i < 24; i++ {
+		v := uint8(8 + 10*i)
+		p = append(p, RGBColor{v, v, v})
+	}
+	return p
+}()
+
+// forDepth returns c as the nearest color the terminal can show.  The basic colors
+// are returned unchanged.
+func (c Color) forDepth(depth ColorDepth) Color {
+	switch {
+	case !c.hasRGB, depth == TrueColor:
+		return c
+	case depth == ANSI256 && strings.HasPrefix(c.extended, "5;"):
+		// already one of the 256 colors
+		return c
+	}
+	q := currentQuantizer()
+	// c.before is 38 for text and 48 for the background
+	bg := c.before - 38
+	switch depth {
+	case ANSI256:
+		return Color{before: c.before, after: c.after, extended: "5;" + strconv.Itoa(16+q(c.rgb, palette256))}
+	}
+	n := q(c.rgb, palette16)
+	if n < 8 {
+		return Color{before: 30 + bg + n, after: c.after}
+	}
+	return Color{before: 90 + bg + n - 8, after: c.after}
+}
+
+// lab is a color in the CIELAB color space
+type lab struct {
+	L, A, B float64
+}
+
+// lab converts c from sRGB to CIELAB under the D65 white point
+func (c RGBColor) lab() lab {
+	linear := func(v uint8) float64 {
+		s := float64(v) / 255
+		if s <= 0.04045 {
+			return s / 12.92
+		}
+		return math.Pow((s+0.055)/1.055, 2.4)
+	}
+	r, g, b := linear(c.R), linear(c.G), linear(c.B)
+	x := (0.4124*r + 0.3576*g + 0.1805*b) / 0.95047
+	y := 0.2126*r + 0.7152*g + 0.0722*b
+	z := (0.0193*r + 0.1192*g + 0.9505*b) / 1.08883
+	f := func(t float64) float64 {
+		if t > 216.0/24389 {
+			return math.Cbrt(t)
+		}
+		return (24389.0/27*t + 16) / 116
+	}
+	fx, fy, fz := f(x), f(y), f(z)
+	return lab{L: 116*fy - 16, A: 500 * (fx - fy), B: 200 * (fy - fz)}
+}
+
+// ciede2000 returns the CIEDE2000 difference between two colors, following Sharma,
+// Wu and Dalal, "The CIEDE2000 Color-Difference Formula"
+func ciede2000(c1, c2 lab) float64 {
+	const pow25_7 = 6103515625 // 25^7
+	rad := math.Pi / 180
+
+	cab := (math.Hypot(c1.A, c1.B) + math.Hypot(c2.A, c2.B)) / 2
+	g := 0.5 * (1 - math.Sqrt(math.Pow(cab, 7)/(math.Pow(cab, 7)+pow25_7)))
+	a1, a2 := (1+g)*c1.A, (1+g)*c2.A
+	ch1, ch2 := math.Hypot(a1, c1.B), math.Hypot(a2, c2.B)
+	hue := func(b, a float64) float64 {
+		if a == 0 && b == 0 {
+			return 0
+		}
+		h := math.Atan2(b, a) / rad
+		if h < 0 {
+			h += 360
+		}
+		return h
+	}
+	h1, h2 := hue(c1.B, a1), hue(c2.B, a2)
+
+	dL := c2.L - c1.L
+	dC := ch2 - ch1
+	var dh float64
+	switch {
+	case ch1*ch2 == 0:
+	case math.Abs(h2-h1) <= 180:
+		dh = h2 - h1
+	case h2-h1 > 180:
+		dh = h2 - h1 - 360
+	default:
+		dh = h2 - h1 + 360
+	}
+	dH := 2 * math.Sqrt(ch1*ch2) * math.Sin(dh/2*rad)
+
+	lMean := (c1.L + c2.L) / 2
+	cMean := (ch1 + ch2) / 2
+	hMean := h1 + h2
+	switch {
+	case ch1*ch2 == 0:
+	case math.Abs(h1-h2) <= 180:
+		hMean /= 2
+	case h1+h2 < 360:
+		hMean = (hMean + 360) / 2
+	default:
+		hMean = (hMean - 360) / 2
+	}
+	t := 1 - 0.17*math.Cos((hMean-30)*rad) + 0.24*math.Cos(2*hMean*rad) +
+		0.32*math.Cos((3*hMean+6)*rad) - 0.20*math.Cos((4*hMean-63)*rad)
+	dTheta := 30 * math.Exp(-math.Pow((hMean-275)/25, 2))
+	rc := 2 * math.Sqrt(math.Pow(cMean, 7)/(math.Pow(cMean, 7)+pow25_7))
+	sl := 1 + 0.015*math.Pow(lMean-50, 2)/math.Sqrt(20+math.Pow(lMean-50, 2))
+	sc := 1 + 0.045*cMean
+	sh := 1 + 0.015*cMean*t
+	rt := -math.Sin(2*dTheta*rad) * rc
+
+	l, cc, hh := dL/sl, dC/sc, dH/sh
+	return math.Sqrt(l*l + cc*cc + hh*hh + rt*cc*hh)
+}
@@ -0,0 +1,60 @@
+package clt
+
+import (
+	"math"
+	"testing"
+)
+
+func TestCIEDE2000(t *testing.T) {
+	// pairs from the test data of Sharma, Wu and Dalal
+	tt := []struct {
+		C1, C2 lab
+		Want   float64
+	}{
+		{C1: lab{50, 2.6772, -79.7751}, C2: lab{50, 0, -82.7485}, Want: 2.0425},
+		{C1: lab{50, 0, 0}, C2: lab{50, -1, 2}, Want: 2.3669},
+		{C1: lab{50, 2.5, 0}, C2: lab{73, 25, -18}, Want: 27.1492},
+		{C1: lab{2.0776, 0.0795, -1.1350}, C2: lab{0.9033, -0.0636, -0.5514}, Want: 0.9082},
+	}
+	for _, tc := range tt {
+		if got := ciede2000(tc.C1, tc.C2); math.Abs(got-tc.Want) > 0.0001 {
+			t.Errorf("Expected %v between %v and %v, got %v", tc.Want, tc.C1, tc.C2, got)
+		}
+	}
+}
+
+func TestForDepth(t *testing.T) {
+	tt := []struct {
+		Name  string
+		Color Color
+		Depth ColorDepth
+		Want  Color
+	}{
+		{Name: "truecolor", Color: RGB(255, 128, 0), Depth: TrueColor, Want: RGB(255, 128, 0)},
+		{Name: "256", Color: RGB(255, 128, 0), Depth: ANSI256, Want: Color{before: 38, after: 39, extended: "5;208"}},
+		{Name: "16", Color: RGB(250, 250, 250), Depth: ANSI16, Want: Color{before: 97, after: 39}},
+		{Name: "16 background", Color: Background(RGB(0, 0, 0)), Depth: ANSI16, Want: Color{before: 40, after: 49}},
+		{Name: "basic", Color: Red, Depth: ANSI16, Want: Red},
+	}
+	for _, tc := range tt {
+		t.Run(tc.Name, func(t *testing.T) {
+			got := tc.Color.forDepth(tc.Depth)
+			if got.before != tc.Want.before || got.after != tc.Want.after || got.extended != tc.Want.extended {
+				t.Errorf("Expected %+v, got %+v", tc.Want, got)
+			}
+		})
+	}
+}
+
+func TestSetQuantizer(t *testing.T) {
+	defer SetQuantizer(nil)
+	first := func(c RGBColor, palette []RGBColor) int { return 0 }
+	SetQuantizer(first)
+	if got := RGB(255, 255, 255).forDepth(ANSI16); got.before != 30 {
+		t.Errorf("Expected the quantizer to pick black, got %+v", got)
+	}
+	SetQuantizer(NearestRGB)
+	if got := NearestRGB(RGBColor{250, 10, 10}, palette16); got != 9 {
+		t.Errorf("Expected bright red, got %d", got)
+	}
+}
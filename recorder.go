@@ -0,0 +1,69 @@
+package clt
+
+import (
+	"bytes"
+	"io"
+	"sync"
+)
+
+// Recorder captures a transcript of a terminal session.  Output written to the
+// recorder is passed through unchanged to the underlying writer, but every
+// exported copy of the transcript has registered secrets replaced with Mask.
+type Recorder struct {
+	w   io.Writer
+	mtx sync.Mutex
+	buf bytes.Buffer
+}
+
+// NewRecorder returns a recorder that writes through to w
+func NewRecorder(w io.Writer) *Recorder {
+	return &Recorder{w: w}
+}
+
+// Write writes p to the underlying writer and appends it to the transcript
+func (r *Recorder) Write(p []byte) (int, error) {
+	r.mtx.Lock()
+	r.buf.Write(p)
+	r.mtx.Unlock()
+	if r.w == nil {
+		return len(p), nil
+	}
+	return r.w.Write(p)
+}
+
+// Input returns a reader that adds everything read from in to the transcript
+// without echoing it to the underlying writer
+func (r *Recorder) Input(in io.Reader) io.Reader {
+	return io.TeeReader(in, transcriptWriter{r})
+}
+
+// Transcript returns the recorded session with all registered secrets redacted
+func (r *Recorder) Transcript() string {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	return Redact(r.buf.String())
+}
+
+// WriteTo writes the redacted transcript to w
+func (r *Recorder) WriteTo(w io.Writer) (int64, error) {
+	n, err := io.WriteString(w, r.Transcript())
+	return int64(n), err
+}
+
+// Reset discards the recorded transcript
+func (r *Recorder) Reset() {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	r.buf.Reset()
+}
+
+// transcriptWriter appends to the transcript only
+type transcriptWriter struct {
+	r *Recorder
+}
+
+func (t transcriptWriter) Write(p []byte) (int, error) {
+	t.r.mtx.Lock()
+	defer t.r.mtx.Unlock()
+	return t.r.buf.Write(p)
+}
@@ -0,0 +1,38 @@
+package clt
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestRecorderRedactsSecrets(t *testing.T) {
+	withSecrets(t)
+	var out bytes.Buffer
+	rec := NewRecorder(&out)
+	RegisterSecret("hunter2")
+
+	// secrets split across writes are still redacted in the transcript
+	rec.Write([]byte("your password is hun"))
+	rec.Write([]byte("ter2\n"))
+	in := rec.Input(strings.NewReader("token hunter2\n"))
+	buf := make([]byte, 64)
+	in.Read(buf)
+
+	if got := out.String(); got != "your password is hunter2\n" {
+		t.Errorf("Recorder should pass output through unchanged, got %q", got)
+	}
+	want := "your password is ****\ntoken ****\n"
+	if got := rec.Transcript(); got != want {
+		t.Errorf("Expected transcript %q, got %q", want, got)
+	}
+}
+
+func TestRedactLongestFirst(t *testing.T) {
+	withSecrets(t)
+	RegisterSecret("abc")
+	RegisterSecret("abcdef")
+	if got := Redact("key=abcdef"); got != "key=****" {
+		t.Errorf("Expected key=****, got %s", got)
+	}
+}
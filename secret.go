@@ -0,0 +1,66 @@
+package clt
+
+import (
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Mask is the replacement text for redacted secrets
+const Mask = "****"
+
+var secrets = struct {
+	sync.RWMutex
+	values map[string]struct{}
+}{values: make(map[string]struct{})}
+
+// RegisterSecret adds s to the set of strings that are redacted from recorded
+// transcripts and other exported artifacts.  Input collected from password prompts
+// is registered automatically when the session is recorded.
+func RegisterSecret(s string) {
+	if len(s) == 0 {
+		return
+	}
+	secrets.Lock()
+	defer secrets.Unlock()
+	secrets.values[s] = struct{}{}
+}
+
+// Redact returns s with every registered secret replaced by Mask
+func Redact(s string) string {
+	secrets.RLock()
+	defer secrets.RUnlock()
+	if len(secrets.values) == 0 {
+		return s
+	}
+
+	// replace the longest secrets first so that a secret that contains
+	// another one is not left partially exposed
+	all := make([]string, 0, len(secrets.values))
+	for secret := range secrets.values {
+		all = append(all, secret)
+	}
+	sort.Slice(all, func(i, j int) bool { return len(all[i]) > len(all[j]) })
+	for _, secret := range all {
+		s = strings.Replace(s, secret, Mask, -1)
+	}
+	return s
+}
+
+// Secret is a string such as an API token that is masked whenever it is printed
+// with the fmt package or rendered by a widget, so that it can't be accidentally
+// exposed on a shared screen.  Use Reveal to get the underlying value.
+type Secret string
+
+// String returns Mask instead of the secret value
+func (s Secret) String() string { return Mask }
+
+// GoString returns Mask instead of the secret value when printed with %#v
+func (s Secret) GoString() string { return Mask }
+
+// Reveal returns the secret value.  Revealed secrets are registered with
+// RegisterSecret so that they are still redacted from recorded transcripts.
+func (s Secret) Reveal() string {
+	RegisterSecret(string(s))
+	return string(s)
+}
@@ -0,0 +1,36 @@
+package clt
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestSecretMasked(t *testing.T) {
+	s := Secret("tok_123")
+	for _, got := range []string{fmt.Sprintf("%s", s), fmt.Sprintf("%v", s), fmt.Sprintf("%#v", s), SStyled(s.String(), Bold)} {
+		if got != Mask && got != SStyled(Mask, Bold) {
+			t.Errorf("Secret should be masked, got %s", got)
+		}
+	}
+	if s.Reveal() != "tok_123" {
+		t.Errorf("Reveal should return the secret value, got %s", s.Reveal())
+	}
+	if got := Redact("token is tok_123"); got != "token is ****" {
+		t.Errorf("Revealed secret should be redacted, got %s", got)
+	}
+}
+
+// withSecrets restores the registered secrets when the test ends
+func withSecrets(t *testing.T) {
+	secrets.Lock()
+	saved := make(map[string]struct{}, len(secrets.values))
+	for s := range secrets.values {
+		saved[s] = struct{}{}
+	}
+	secrets.Unlock()
+	t.Cleanup(func() {
+		secrets.Lock()
+		defer secrets.Unlock()
+		secrets.values = saved
+	})
+}
@@ -0,0 +1,301 @@
+package clt
+
+import (
+	"io"
+	"strconv"
+	"strings"
+)
+
+// MinimizeStyles returns a writer that keeps track of the styles set by the ANSI
+// escape sequences written through it and only sends the changes between them.
+// Styles that end just before the same styles start again, as between the cells of a
+// table, are left out entirely.  Progress indicators already write through one when
+// the output is a terminal, which also replaces 256 and RGB colors from any source
+// with the nearest the terminal can show.
+func MinimizeStyles(w io.Writer) io.Writer {
+	if _, ok := w.(*styleWriter); ok {
+		return w
+	}
+	return &styleWriter{w: w, depth: TrueColor, attrs: allAttributes}
+}
+
+// sgrState is the styling in effect after a series of SGR escape sequences
+type sgrState struct {
+	bold, dim, italic, underline, blink, reverse, hidden, strike, overline bool
+	// fg and bg are the parameters that select the color, or empty for the default
+	fg, bg string
+	// unknown is set when a sequence included styles that aren't tracked, so that
+	// only a full reset is sure to end them
+	unknown bool
+}
+
+// apply updates the state with the parameters of an SGR sequence.  It returns false
+// if any of them aren't tracked, in which case the rest are still applied.
+func (s *sgrState) apply(params string) bool {
+	ok := true
+	p := strings.Split(params, ";")
+	for i := 0; i < len(p); i++ {
+		n, err := strconv.Atoi(p[i])
+		switch {
+		case len(p[i]) == 0, n == 0 && err == nil:
+			*s = sgrState{}
+		case err != nil:
+			ok = false
+		case n == 1:
+			s.bold = true
+		case n == 2:
+			s.dim = true
+		case n == 22:
+			s.bold, s.dim = false, false
+		case n == 3, n == 23:
+			s.italic = n == 3
+		case n == 4, n == 24:
+			s.underline = n == 4
+		case n == 5, n == 25:
+			s.blink = n == 5
+		case n == 7, n == 27:
+			s.reverse = n == 7
+		case n == 8, n == 28:
+			s.hidden = n == 8
+		case n == 9, n == 29:
+			s.strike = n == 9
+		case n == 53, n == 55:
+			s.overline = n == 53
+		case n >= 30 && n <= 37, n >= 90 && n <= 97:
+			s.fg = p[i]
+		case n == 39:
+			s.fg = ""
+		case n >= 40 && n <= 47, n >= 100 && n <= 107:
+			s.bg = p[i]
+		case n == 49:
+			s.bg = ""
+		case n == 38, n == 48:
+			// 5;n selects from the 256 color palette and 2;r;g;b an RGB color
+			args := 0
+			switch {
+			case i+1 < len(p) && p[i+1] == "5":
+				args = 2
+			case i+1 < len(p) && p[i+1] == "2":
+				args = 4
+			}
+			if args == 0 || i+args >= len(p) {
+				ok = false
+				i = len(p)
+				break
+			}
+			color := strings.Join(p[i:i+args+1], ";")
+			if n == 38 {
+				s.fg = color
+			} else {
+				s.bg = color
+			}
+			i += args
+		default:
+			ok = false
+		}
+	}
+	if !ok {
+		s.unknown = true
+	}
+	return ok
+}
+
+// forDepth returns s with colors the terminal can't show replaced by the nearest it
+// has, or without colors at NoColor
+func (s sgrState) forDepth(depth ColorDepth) sgrState {
+	if depth == NoColor {
+		s.fg, s.bg = "", ""
+		return s
+	}
+	s.fg = colorForDepth(s.fg, depth)
+	s.bg = colorForDepth(s.bg, depth)
+	return s
+}
+
+// forAttributes returns s with textstyles the terminal can't show replaced: italic
+// by underline and strikethrough by dim, while overlines are left out
+func (s sgrState) forAttributes(a attributeSupport) sgrState {
+	if s.italic && !a.italic {
+		s.italic, s.underline = false, true
+	}
+	if s.strike && !a.strikethrough {
+		s.strike, s.dim = false, true
+	}
+	if !a.overline {
+		s.overline = false
+	}
+	return s
+}
+
+// colorForDepth returns the parameters of a 256 or RGB color as those of the nearest
+// color the terminal can show.  Other parameters are returned unchanged.
+func colorForDepth(params string, depth ColorDepth) string {
+	p := strings.Split(params, ";")
+	if depth == TrueColor || len(p) < 3 || (p[0] != "38" && p[0] != "48") {
+		return params
+	}
+	n := make([]uint8, len(p)-2)
+	for i := range n {
+		v, _ := strconv.Atoi(p[i+2])
+		n[i] = uint8(v)
+	}
+	var c Color
+	switch {
+	case p[1] == "5" && len(n) == 1:
+		c = Color256(n[0])
+	case p[1] == "2" && len(n) == 3:
+		c = RGB(n[0], n[1], n[2])
+	default:
+		return params
+	}
+	if p[0] == "48" {
+		c = Background(c)
+	}
+	c = c.forDepth(depth)
+	if len(c.extended) > 0 {
+		return strconv.Itoa(c.before) + ";" + c.extended
+	}
+	return strconv.Itoa(c.before)
+}
+
+// codes returns the parameters that set every style in s from no styling
+func (s sgrState) codes() []string {
+	var codes []string
+	for _, c := range []struct {
+		on   bool
+		code string
+	}{{s.bold, "1"}, {s.dim, "2"}, {s.italic, "3"}, {s.underline, "4"}, {s.blink, "5"}, {s.reverse, "7"}, {s.hidden, "8"}, {s.strike, "9"}, {s.overline, "53"}, {len(s.fg) > 0, s.fg}, {len(s.bg) > 0, s.bg}} {
+		if c.on {
+			codes = append(codes, c.code)
+		}
+	}
+	return codes
+}
+
+// sgrTransition returns the shortest sequence that changes the styling from one state
+// to another, or an empty string if they are the same
+func sgrTransition(from, to sgrState) string {
+	if from == to {
+		return ""
+	}
+	reset := "\x1b[" + strings.Join(append([]string{"0"}, to.codes()...), ";") + "m"
+	if from.unknown && !to.unknown {
+		return reset
+	}
+	var codes []string
+	if (from.bold && !to.bold) || (from.dim && !to.dim) {
+		// 22 ends both bold and dim
+		codes = append(codes, "22")
+		from.bold, from.dim = false, false
+	}
+	toggle := func(from, to bool, on, off string) {
+		switch {
+		case to && !from:
+			codes = append(codes, on)
+		case from && !to:
+			codes = append(codes, off)
+		}
+	}
+	toggle(from.bold, to.bold, "1", "22")
+	toggle(from.dim, to.dim, "2", "22")
+	toggle(from.italic, to.italic, "3", "23")
+	toggle(from.underline, to.underline, "4", "24")
+	toggle(from.blink, to.blink, "5", "25")
+	toggle(from.reverse, to.reverse, "7", "27")
+	toggle(from.hidden, to.hidden, "8", "28")
+	toggle(from.strike, to.strike, "9", "29")
+	toggle(from.overline, to.overline, "53", "55")
+	color := func(from, to, def string) {
+		switch {
+		case from == to:
+		case len(to) == 0:
+			codes = append(codes, def)
+		default:
+			codes = append(codes, to)
+		}
+	}
+	color(from.fg, to.fg, "39")
+	color(from.bg, to.bg, "49")
+	if len(codes) == 0 {
+		return ""
+	}
+	if changes := "\x1b[" + strings.Join(codes, ";") + "m"; len(changes) <= len(reset) {
+		return changes
+	}
+	return reset
+}
+
+// styleWriter holds back SGR escape sequences until the next character is written,
+// then sends only the difference between the styling already on the terminal and the
+// styling that is wanted.  Colors and textstyles the terminal can't show are replaced
+// by the nearest it has.  Other escape sequences are passed through unchanged.
+type styleWriter struct {
+	w io.Writer
+	// depth is the colors the terminal can show
+	depth ColorDepth
+	// attrs is the textstyles the terminal can show
+	attrs attributeSupport
+	// cur is the styling sent to the terminal and want is the styling requested
+	cur, want sgrState
+	// esc is an escape sequence that hasn't been completed yet
+	esc []byte
+}
+
+func (s *styleWriter) Write(b []byte) (int, error) {
+	out := make([]byte, 0, len(b))
+	for _, c := range b {
+		switch {
+		case len(s.esc) == 1:
+			s.esc = append(s.esc, c)
+			if c != '[' {
+				out = s.passThrough(out)
+			}
+		case len(s.esc) > 1:
+			s.esc = append(s.esc, c)
+			// parameters and intermediates end at a final byte in 0x40-0x7e
+			if c < 0x40 || c > 0x7e {
+				continue
+			}
+			if c != 'm' {
+				out = s.passThrough(out)
+				continue
+			}
+			next := s.want
+			if !next.apply(string(s.esc[2 : len(s.esc)-1])) {
+				// styles that aren't tracked are sent as they are
+				out = s.passThrough(out)
+				s.cur = next
+			}
+			s.want = next
+			s.esc = nil
+		case c == 0x1b:
+			s.esc = []byte{c}
+		default:
+			out = append(s.flush(out), c)
+		}
+	}
+	// styles are never held back between writes so that they can't leak into output
+	// written some other way
+	if len(s.esc) == 0 {
+		out = s.flush(out)
+	}
+	if _, err := s.w.Write(out); err != nil {
+		return 0, err
+	}
+	return len(b), nil
+}
+
+// flush appends the changes needed to bring the terminal to the wanted styling
+func (s *styleWriter) flush(out []byte) []byte {
+	want := s.want.forDepth(s.depth).forAttributes(s.attrs)
+	out = append(out, sgrTransition(s.cur, want)...)
+	s.cur = want
+	return out
+}
+
+// passThrough sends the pending styling and then the escape sequence unchanged
+func (s *styleWriter) passThrough(out []byte) []byte {
+	out = append(s.flush(out), s.esc...)
+	s.esc = nil
+	return out
+}
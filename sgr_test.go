@@ -0,0 +1,69 @@
+package clt
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestMinimizeStyles(t *testing.T) {
+	tt := []struct {
+		Name string
+		In   string
+		Want string
+	}{
+		{Name: "plain", In: "abc", Want: "abc"},
+		// ending every style is shortest as a reset
+		{Name: "adjacent", In: "\x1b[31ma\x1b[39m\x1b[31mb\x1b[39m", Want: "\x1b[31mab\x1b[0m"},
+		{Name: "change color", In: "\x1b[1;31ma\x1b[22;39m\x1b[1;32mb\x1b[22;39m", Want: "\x1b[1;31ma\x1b[32mb\x1b[0m"},
+		{Name: "already set", In: "\x1b[1m\x1b[1ma", Want: "\x1b[1ma"},
+		{Name: "no text", In: "\x1b[31m\x1b[39m", Want: ""},
+		{Name: "end dim only", In: "\x1b[1;2ma\x1b[22;1mb\x1b[0m", Want: "\x1b[1;2ma\x1b[0;1mb\x1b[0m"},
+		{Name: "extended", In: "\x1b[38;5;208ma\x1b[39m\x1b[38;5;208mb\x1b[38;2;1;2;3mc\x1b[0m", Want: "\x1b[38;5;208mab\x1b[38;2;1;2;3mc\x1b[0m"},
+		{Name: "reset", In: "\x1b[1;4;31;44ma\x1b[0m", Want: "\x1b[1;4;31;44ma\x1b[0m"},
+		{Name: "cursor movement", In: "\x1b[31m\x1b[2Ka\x1b[39m", Want: "\x1b[31m\x1b[2Ka\x1b[0m"},
+		{Name: "untracked", In: "\x1b[21ma\x1b[0mb", Want: "\x1b[21ma\x1b[0mb"},
+	}
+	for _, tc := range tt {
+		t.Run(tc.Name, func(t *testing.T) {
+			var out bytes.Buffer
+			w := MinimizeStyles(&out)
+			w.Write([]byte(tc.In))
+			if out.String() != tc.Want {
+				t.Errorf("Expected %q, got %q", tc.Want, out.String())
+			}
+		})
+	}
+}
+
+func TestMinimizeStylesAcrossWrites(t *testing.T) {
+	var out bytes.Buffer
+	w := MinimizeStyles(&out)
+	for _, s := range []string{"\x1b[3", "1ma", "\x1b[39m", "\x1b[31mb\x1b[39m"} {
+		w.Write([]byte(s))
+	}
+	// styles are caught up at the end of each write
+	if want := "\x1b[31ma\x1b[0m\x1b[31mb\x1b[0m"; out.String() != want {
+		t.Errorf("Expected %q, got %q", want, out.String())
+	}
+}
+
+func TestStyleWriterDowngrade(t *testing.T) {
+	in := "\x1b[38;2;255;0;0ma\x1b[48;5;21mb\x1b[0m"
+	tt := []struct {
+		Depth ColorDepth
+		Want  string
+	}{
+		{Depth: TrueColor, Want: in},
+		{Depth: ANSI256, Want: "\x1b[38;5;196ma\x1b[48;5;21mb\x1b[0m"},
+		{Depth: ANSI16, Want: "\x1b[91ma\x1b[44mb\x1b[0m"},
+		{Depth: NoColor, Want: "ab"},
+	}
+	for _, tc := range tt {
+		var out bytes.Buffer
+		w := &styleWriter{w: &out, depth: tc.Depth, attrs: allAttributes}
+		w.Write([]byte(in))
+		if out.String() != tc.Want {
+			t.Errorf("Expected %q for %s, got %q", tc.Want, tc.Depth, out.String())
+		}
+	}
+}
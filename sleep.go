@@ -0,0 +1,65 @@
+package clt
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+)
+
+// SleepWithProgress waits for d while showing a bar that counts down the time left,
+// for deliberate waits such as rate limits.  When stdin is a terminal, pressing any
+// key ends the wait early and nil is returned.  If ctx is canceled first, the bar
+// fails and ctx.Err() is returned.
+func SleepWithProgress(ctx context.Context, d time.Duration, msg string, opts ...ProgressOption) error {
+	const step = 100 * time.Millisecond
+
+	hint := ""
+	restore := func() {}
+	fd := int(os.Stdin.Fd())
+	keys := false
+	if isTerminal(os.Stdin) {
+		if r, err := setCbreak(fd); err == nil {
+			restore, keys, hint = r, true, ", press any key to skip"
+		}
+	}
+
+	p := NewProgressBar("%s", msg)
+	p.apply(opts)
+	p.Start()
+	start := time.Now()
+	for {
+		elapsed := time.Since(start)
+		if elapsed >= d {
+			restore()
+			return p.Success()
+		}
+		left := (d - elapsed + time.Second - 1).Truncate(time.Second)
+		p.UpdatePrompt(fmt.Sprintf("%s (%s left%s)", msg, formatElapsed(left), hint))
+		p.Update(float64(elapsed) / float64(d))
+
+		wait := step
+		if remaining := d - elapsed; remaining < wait {
+			wait = remaining
+		}
+		switch {
+		case keys:
+			if keyPressed(fd, wait) {
+				restore()
+				return p.Success()
+			}
+			if ctx.Err() != nil {
+				restore()
+				p.Fail()
+				return ctx.Err()
+			}
+		default:
+			select {
+			case <-ctx.Done():
+				p.Fail()
+				return ctx.Err()
+			case <-time.After(wait):
+			}
+		}
+	}
+}
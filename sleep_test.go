@@ -0,0 +1,33 @@
+package clt
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSleepWithProgress(t *testing.T) {
+	out := bytes.NewBuffer(nil)
+	start := time.Now()
+	if err := SleepWithProgress(context.Background(), 250*time.Millisecond, "Waiting for rate limit", WithProgressOutput(out)); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed < 250*time.Millisecond {
+		t.Errorf("Expected to sleep for the full duration, slept %s", elapsed)
+	}
+	if !strings.Contains(out.String(), "Waiting for rate limit (00:01 left)") {
+		t.Errorf("Expected a countdown in the prompt, got %q", out.String())
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start = time.Now()
+	if err := SleepWithProgress(ctx, time.Minute, "Waiting", WithProgressOutput(bytes.NewBuffer(nil))); err != context.DeadlineExceeded {
+		t.Errorf("Expected the context error, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Expected cancellation to end the wait early, slept %s", elapsed)
+	}
+}
@@ -0,0 +1,92 @@
+package clt
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+var (
+	// Moon phases
+	Moon Spinner = []string{"🌑 ", "🌒 ", "🌓 ", "🌔 ", "🌕 ", "🌖 ", "🌗 ", "🌘 "}
+	// Earth turning
+	Earth Spinner = []string{"🌍 ", "🌎 ", "🌏 "}
+	// Arrows pointing around a compass
+	Arrows Spinner = []string{"←", "↖", "↑", "↗", "→", "↘", "↓", "↙"}
+	// GrowingBar that rises and falls
+	GrowingBar Spinner = []string{"▁", "▃", "▄", "▅", "▆", "▇", "█", "▇", "▆", "▅", "▄", "▃"}
+	// BouncingBar that slides back and forth
+	BouncingBar Spinner = []string{"    ", "=   ", "==  ", "=== ", " ===", "  ==", "   =", "    ", "   =", "  ==", " ===", "====", "=== ", "==  ", "=   "}
+	// Triangle that turns corner to corner
+	Triangle Spinner = []string{"◢", "◣", "◤", "◥"}
+	// Star that twinkles
+	Star Spinner = []string{"✶", "✸", "✹", "✺", "✹", "✷"}
+	// FilledDots with one dot missing that spins around
+	FilledDots Spinner = []string{"⣾", "⣽", "⣻", "⢿", "⡿", "⣟", "⣯", "⣷"}
+	// Dots12 with two dots chasing each other across two columns
+	Dots12 Spinner = []string{
+		"⢀⠀", "⡀⠀", "⠄⠀", "⢂⠀", "⡂⠀", "⠅⠀", "⢃⠀", "⡃⠀", "⠍⠀", "⢋⠀", "⡋⠀", "⠍⠁", "⢋⠁", "⡋⠁",
+		"⠍⠉", "⠋⠉", "⠋⠉", "⠉⠙", "⠉⠙", "⠉⠩", "⠈⢙", "⠈⡙", "⢈⠩", "⡀⢙", "⠄⡙", "⢂⠩", "⡂⢘", "⠅⡘",
+		"⢃⠨", "⡃⢐", "⠍⡐", "⢋⠠", "⡋⢀", "⠍⡁", "⢋⠁", "⡋⠁", "⠍⠉", "⠋⠉", "⠋⠉", "⠉⠙", "⠉⠙", "⠉⠩",
+		"⠈⢙", "⠈⡙", "⠈⠩", "⠀⢙", "⠀⡙", "⠀⠩", "⠀⢘", "⠀⡘", "⠀⠨", "⠀⢐", "⠀⡐", "⠀⠠", "⠀⢀", "⠀⡀",
+	}
+)
+
+// spinners are the spinners that can be looked up by name.  Names from the
+// cli-spinners collection are included so that configs written for other tools work.
+var spinners = struct {
+	sync.RWMutex
+	byName map[string]Spinner
+}{byName: map[string]Spinner{
+	"wheel":        Wheel,
+	"line":         Wheel,
+	"bouncing":     Bouncing,
+	"clock":        Clock,
+	"dots":         Dots,
+	"dots2":        FilledDots,
+	"filleddots":   FilledDots,
+	"dots12":       Dots12,
+	"moon":         Moon,
+	"earth":        Earth,
+	"arrow":        Arrows,
+	"arrows":       Arrows,
+	"growingbar":   GrowingBar,
+	"growvertical": GrowingBar,
+	"bouncingbar":  BouncingBar,
+	"triangle":     Triangle,
+	"star":         Star,
+}}
+
+// SpinnerByName returns the built-in or registered spinner called name, ignoring
+// case, so that the spinner can be chosen in a config file
+func SpinnerByName(name string) (Spinner, error) {
+	spinners.RLock()
+	defer spinners.RUnlock()
+	s, ok := spinners.byName[strings.ToLower(strings.TrimSpace(name))]
+	if !ok {
+		return nil, fmt.Errorf("unknown spinner %q", name)
+	}
+	return s, nil
+}
+
+// RegisterSpinner adds a spinner that can be looked up with SpinnerByName, replacing
+// any spinner with the same name
+func RegisterSpinner(name string, s Spinner) {
+	spinners.Lock()
+	defer spinners.Unlock()
+	spinners.byName[strings.ToLower(name)] = s
+}
+
+// SpinnerNames returns the names accepted by SpinnerByName in sorted order, such as
+// for listing the choices in help text
+func SpinnerNames() []string {
+	spinners.RLock()
+	defer spinners.RUnlock()
+	names := make([]string, 0, len(spinners.byName))
+	for name := range spinners.byName {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
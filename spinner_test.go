@@ -0,0 +1,37 @@
+package clt
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSpinnerByName(t *testing.T) {
+	s, err := SpinnerByName(" Dots12 ")
+	if err != nil || !reflect.DeepEqual(s, Dots12) {
+		t.Errorf("Expected the dots12 spinner, got %v %v", s, err)
+	}
+	if _, err := SpinnerByName("nope"); err == nil {
+		t.Errorf("Expected an unknown spinner to return an error")
+	}
+
+	t.Cleanup(func() {
+		spinners.Lock()
+		defer spinners.Unlock()
+		delete(spinners.byName, "pulse")
+	})
+	RegisterSpinner("Pulse", Spinner{"·", "•", "●", "•"})
+	if s, err := SpinnerByName("pulse"); err != nil || len(s) != 4 {
+		t.Errorf("Expected the registered spinner, got %v %v", s, err)
+	}
+
+	// every frame of a spinner is the same width so the line doesn't jitter
+	for _, name := range SpinnerNames() {
+		s, _ := SpinnerByName(name)
+		for _, step := range s {
+			if displayWidth(step) != displayWidth(s[0]) {
+				t.Errorf("Expected the frames of %s to be the same width, got %q and %q", name, s[0], step)
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package clt
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// Step markers shown when a step finishes
+const (
+	StepDone   = "✓"
+	StepFailed = "✗"
+)
+
+// Steps is a checklist of named steps that are run in order.  Each step is shown
+// with a spinner while it runs that resolves to ✓ or ✗, and finished steps stay on
+// screen so the list reads as a record of what was done.
+type Steps struct {
+	// ContinueOnError runs the remaining steps after a step fails instead of
+	// stopping at the first failure
+	ContinueOnError bool
+	// OfferRetry shows a summary after steps fail and asks whether to run the failed
+	// steps again, until they all succeed or the user declines
+	OfferRetry bool
+
+	steps  []step
+	input  *bufio.Reader
+	output io.Writer
+}
+
+type step struct {
+	name string
+	fn   func() error
+}
+
+// NewSteps returns an empty checklist
+func NewSteps() *Steps {
+	return &Steps{input: bufio.NewReader(os.Stdin), output: os.Stdout}
+}
+
+// Add registers a step named name that is run by fn.  It returns the checklist so
+// that calls can be chained.
+func (s *Steps) Add(name string, fn func() error) *Steps {
+	s.steps = append(s.steps, step{name: name, fn: fn})
+	return s
+}
+
+// Run runs every step in the order it was added.  It returns the error of the first
+// step that fails.  Unless ContinueOnError is set, the steps after a failure are not
+// run.  With OfferRetry, the failed steps can be run again and the error is that of
+// the first step still failing when the user stops retrying.
+func (s *Steps) Run() error {
+	errs := make([]error, len(s.steps))
+	ran := make([]bool, len(s.steps))
+	pending := make([]int, len(s.steps))
+	for i := range s.steps {
+		pending[i] = i
+	}
+	for {
+		for _, i := range pending {
+			errs[i], ran[i] = s.run(s.steps[i]), true
+			if errs[i] != nil && !s.ContinueOnError {
+				break
+			}
+		}
+		pending = unfinishedSteps(errs, ran)
+		if len(pending) == 0 || !s.OfferRetry {
+			break
+		}
+		s.summarize(errs, ran)
+		if !confirmPrompt(s.input, s.output, "Retry failed steps?", false) {
+			break
+		}
+		fmt.Fprintln(s.output)
+	}
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// unfinishedSteps returns the index of each step that failed or hasn't been run
+func unfinishedSteps(errs []error, ran []bool) []int {
+	var unfinished []int
+	for i := range errs {
+		if errs[i] != nil || !ran[i] {
+			unfinished = append(unfinished, i)
+		}
+	}
+	return unfinished
+}
+
+// summarize shows how many steps succeeded and why the others failed
+func (s *Steps) summarize(errs []error, ran []bool) {
+	var done, notRun int
+	var failures []string
+	for i, err := range errs {
+		switch {
+		case err != nil:
+			failures = append(failures, fmt.Sprintf("  %s %s: %s", CurrentTheme().Error.ApplyTo(StepFailed), s.steps[i].name, err))
+		case ran[i]:
+			done++
+		default:
+			notRun++
+		}
+	}
+	counts := fmt.Sprintf("%d done, %d failed", done, len(failures))
+	if notRun > 0 {
+		counts += fmt.Sprintf(", %d not run", notRun)
+	}
+	fmt.Fprintf(s.output, "\n%s\n%s\n", counts, strings.Join(failures, "\n"))
+}
+
+func (s *Steps) run(st step) error {
+	p := NewProgressSpinner("%s", st.name)
+	p.output = s.output
+	p.Start()
+	err := st.fn()
+	if err != nil {
+		p.FailWith(StepFailed)
+		return err
+	}
+	p.SuccessWith(StepDone)
+	return nil
+}
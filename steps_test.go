@@ -0,0 +1,87 @@
+package clt
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestSteps(t *testing.T) {
+	out := bytes.NewBuffer(nil)
+	errBuild := errors.New("build failed")
+	var ran []string
+
+	s := NewSteps()
+	s.output = out
+	s.Add("fetch", func() error { ran = append(ran, "fetch"); return nil }).
+		Add("build", func() error { ran = append(ran, "build"); return errBuild }).
+		Add("deploy", func() error { ran = append(ran, "deploy"); return nil })
+
+	if err := s.Run(); err != errBuild {
+		t.Errorf("Expected %v, got %v", errBuild, err)
+	}
+	if strings.Join(ran, ",") != "fetch,build" {
+		t.Errorf("Expected steps after the failure to be skipped, ran %v", ran)
+	}
+	lines := strings.Split(out.String(), "\n")
+	if !strings.HasSuffix(lines[0], Styled(Green).ApplyTo(StepDone)+"]") {
+		t.Errorf("Expected fetch to be marked done, got %q", lines[0])
+	}
+	if !strings.HasSuffix(lines[1], Styled(Red).ApplyTo(StepFailed)+"]") {
+		t.Errorf("Expected build to be marked failed, got %q", lines[1])
+	}
+
+	ran = nil
+	s.ContinueOnError = true
+	if err := s.Run(); err != errBuild {
+		t.Errorf("Expected %v, got %v", errBuild, err)
+	}
+	if strings.Join(ran, ",") != "fetch,build,deploy" {
+		t.Errorf("Expected all steps to run, ran %v", ran)
+	}
+}
+
+func TestStepsRetry(t *testing.T) {
+	out := bytes.NewBuffer(nil)
+	attempts := map[string]int{}
+	// each step fails until it has been tried the given number of times
+	flaky := func(name string, tries int) func() error {
+		return func() error {
+			attempts[name]++
+			if attempts[name] < tries {
+				return errors.New(name + " is flaky")
+			}
+			return nil
+		}
+	}
+
+	s := NewSteps()
+	s.output = out
+	s.input = bufio.NewReader(strings.NewReader("y\ny\n"))
+	s.OfferRetry = true
+	s.Add("fetch", flaky("fetch", 1)).
+		Add("build", flaky("build", 3)).
+		Add("deploy", flaky("deploy", 1))
+
+	if err := s.Run(); err != nil {
+		t.Errorf("Expected the retries to succeed, got %v", err)
+	}
+	if attempts["fetch"] != 1 || attempts["build"] != 3 || attempts["deploy"] != 1 {
+		t.Errorf("Expected only the failed and skipped steps to be retried, got %v", attempts)
+	}
+	if !strings.Contains(out.String(), "1 done, 1 failed, 1 not run\n  "+Styled(Red).ApplyTo(StepFailed)+" build: build is flaky\n") {
+		t.Errorf("Expected a summary of the failure, got %q", out.String())
+	}
+	if strings.Count(out.String(), "Retry failed steps?") != 2 {
+		t.Errorf("Expected to be asked to retry twice, got %q", out.String())
+	}
+
+	attempts = map[string]int{}
+	s.ContinueOnError = true
+	s.input = bufio.NewReader(strings.NewReader("n\n"))
+	if err := s.Run(); err == nil || err.Error() != "build is flaky" {
+		t.Errorf("Expected the declined retry to return the failure, got %v", err)
+	}
+}
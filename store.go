@@ -0,0 +1,116 @@
+package clt
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// Store remembers the user's choices between runs: the tips they have dismissed, the
+// selections made with RememberSelection and the theme picked with ChooseTheme.  Set
+// one with SetStore to keep them somewhere other than the default file.
+type Store interface {
+	// Get returns the value saved for key.  ok is false if nothing was saved.
+	Get(key string) (value string, ok bool)
+	// Set saves value for key
+	Set(key string, value string) error
+}
+
+// FileStore returns a Store that keeps values as JSON in the file at path.  The
+// directory is created when the first value is saved.
+func FileStore(path string) Store {
+	return &fileStore{path: path}
+}
+
+type fileStore struct {
+	path   string
+	mtx    sync.Mutex
+	values map[string]string
+}
+
+// load reads the file the first time it is needed.  A missing or unreadable file
+// is treated as empty.  Must be called with the mutex held.
+func (f *fileStore) load() {
+	if f.values != nil {
+		return
+	}
+	f.values = make(map[string]string)
+	if data, err := os.ReadFile(f.path); err == nil {
+		json.Unmarshal(data, &f.values)
+	}
+}
+
+func (f *fileStore) Get(key string) (string, bool) {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	f.load()
+	v, ok := f.values[key]
+	return v, ok
+}
+
+func (f *fileStore) Set(key string, value string) error {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	f.load()
+	f.values[key] = value
+	data, err := json.MarshalIndent(f.values, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(f.path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(f.path, data, 0644)
+}
+
+// store is the Store set with SetStore, or nil until the default is needed
+var store = struct {
+	sync.Mutex
+	s Store
+}{}
+
+// SetStore sets where the user's choices are remembered.  It defaults to a file named
+// after the program under os.UserConfigDir, such as ~/.config/mytool/clt.json.
+func SetStore(s Store) {
+	store.Lock()
+	defer store.Unlock()
+	store.s = s
+}
+
+// preferences returns the Store set with SetStore or the default file store.  If
+// there is no config directory, choices are only remembered for this run.
+func preferences() Store {
+	store.Lock()
+	defer store.Unlock()
+	if store.s == nil {
+		dir, err := os.UserConfigDir()
+		switch {
+		case err != nil:
+			store.s = &memoryStore{values: make(map[string]string)}
+		default:
+			store.s = FileStore(filepath.Join(dir, filepath.Base(os.Args[0]), "clt.json"))
+		}
+	}
+	return store.s
+}
+
+// memoryStore is a Store that forgets everything when the program exits
+type memoryStore struct {
+	mtx    sync.Mutex
+	values map[string]string
+}
+
+func (m *memoryStore) Get(key string) (string, bool) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	v, ok := m.values[key]
+	return v, ok
+}
+
+func (m *memoryStore) Set(key string, value string) error {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	m.values[key] = value
+	return nil
+}
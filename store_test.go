@@ -0,0 +1,42 @@
+package clt
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestFileStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mytool", "clt.json")
+	s := FileStore(path)
+	if _, ok := s.Get("theme"); ok {
+		t.Errorf("Expected nothing to be saved yet")
+	}
+	if err := s.Set("theme", "dark"); err != nil {
+		t.Fatal(err)
+	}
+
+	// a new store reads what was saved
+	if v, ok := FileStore(path).Get("theme"); !ok || v != "dark" {
+		t.Errorf("Expected the saved value, got %q %v", v, ok)
+	}
+}
+
+func TestTip(t *testing.T) {
+	out := withNoticeOutput()
+	SetStore(FileStore(filepath.Join(t.TempDir(), "clt.json")))
+	defer SetStore(nil)
+
+	if !Tip("watch", "Use --watch to rerun on changes") || Tip("watch", "Use --watch to rerun on changes") {
+		t.Errorf("Expected the tip to be shown once per invocation")
+	}
+	if out.String() != "\x1b[36mTip:\x1b[39m \x1b[2mUse --watch to rerun on changes\x1b[22m\n" {
+		t.Errorf("Expected the tip, got %q", out.String())
+	}
+
+	if err := DismissTip("filters"); err != nil {
+		t.Fatal(err)
+	}
+	if Tip("filters", "Type / to filter") {
+		t.Errorf("Expected a dismissed tip to stay hidden")
+	}
+}
@@ -3,17 +3,45 @@ package clt
 import (
 	"bytes"
 	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
 )
 
 // Color represents a ANSI-coded color style for text
 type Color struct {
 	before int
 	after  int
+	// extended holds the parameters that follow before for a 256 color or RGB value
+	extended string
+	// rgb is the value of colors from RGB and Color256, which set hasRGB, so that
+	// they can be matched to a smaller palette.  It is held by value so that colors
+	// can be compared with ==.
+	rgb    RGBColor
+	hasRGB bool
 }
 
-// Codes returns ANSI styling values for a color
+// Codes returns ANSI styling values for a color.  For a color from Color256 or RGB,
+// these only select the extended palette and Styled adds the rest.
 func (c Color) Codes() (int, int) { return c.before, c.after }
 
+// Color256 returns one of the 256 colors of the xterm palette.  The first 16 are the
+// basic colors and their bright versions, then a 6x6x6 color cube and a grayscale ramp.
+func Color256(n uint8) Color {
+	rgb := palette16[n%16]
+	if n >= 16 {
+		rgb = palette256[n-16]
+	}
+	return Color{before: 38, after: 39, extended: fmt.Sprintf("5;%d", n), rgb: rgb, hasRGB: true}
+}
+
+// RGB returns a 24-bit truecolor value.  On terminals without truecolor, the nearest
+// of the colors they have is shown instead, see SetQuantizer.
+func RGB(r, g, b uint8) Color {
+	return Color{before: 38, after: 39, extended: fmt.Sprintf("2;%d;%d;%d", r, g, b), rgb: RGBColor{r, g, b}, hasRGB: true}
+}
+
 // Textstyle represents a ANSI-coded text style
 type Textstyle struct {
 	before int
@@ -37,8 +65,12 @@ type Style struct {
 }
 
 // ApplyTo applies styles created using the Styled command to a string
-// to generate an styled output using ANSI terminal codes
+// to generate an styled output using ANSI terminal codes.  The content is returned
+// unstyled when color is turned off, see ForceColor.
 func (s *Style) ApplyTo(content string) string {
+	if !colorEnabled() {
+		return content
+	}
 	var out bytes.Buffer
 	out.WriteString(s.before)
 	out.WriteString(content)
@@ -48,31 +80,49 @@ func (s *Style) ApplyTo(content string) string {
 
 var (
 	// Colors
-	Black   = Color{30, 39}
-	Red     = Color{31, 39}
-	Green   = Color{32, 39}
-	Yellow  = Color{33, 39}
-	Blue    = Color{34, 39}
-	Magenta = Color{35, 39}
-	Cyan    = Color{36, 39}
-	White   = Color{37, 39}
-	Default = Color{39, 39}
+	Black   = Color{before: 30, after: 39}
+	Red     = Color{before: 31, after: 39}
+	Green   = Color{before: 32, after: 39}
+	Yellow  = Color{before: 33, after: 39}
+	Blue    = Color{before: 34, after: 39}
+	Magenta = Color{before: 35, after: 39}
+	Cyan    = Color{before: 36, after: 39}
+	White   = Color{before: 37, after: 39}
+	Default = Color{before: 39, after: 39}
 
 	// Shortcut Colors
-	K   = Color{30, 39}
-	R   = Color{31, 39}
-	G   = Color{32, 39}
-	Y   = Color{33, 39}
-	B   = Color{34, 39}
-	M   = Color{35, 39}
-	C   = Color{36, 39}
-	W   = Color{37, 39}
-	Def = Color{39, 39}
-
-	// Textstyles
-	Bold      = Textstyle{1, 22}
-	Italic    = Textstyle{3, 23}
-	Underline = Textstyle{4, 24}
+	K   = Color{before: 30, after: 39}
+	R   = Color{before: 31, after: 39}
+	G   = Color{before: 32, after: 39}
+	Y   = Color{before: 33, after: 39}
+	B   = Color{before: 34, after: 39}
+	M   = Color{before: 35, after: 39}
+	C   = Color{before: 36, after: 39}
+	W   = Color{before: 37, after: 39}
+	Def = Color{before: 39, after: 39}
+
+	// Textstyles.  Terminals that can't show italic or strikethrough text get
+	// underlined or dim text instead, and overlines are left out.
+	Bold          = Textstyle{1, 22}
+	Dim           = Textstyle{2, 22}
+	Italic        = Textstyle{3, 23}
+	Underline     = Textstyle{4, 24}
+	Blink         = Textstyle{5, 25}
+	Reverse       = Textstyle{7, 27}
+	Hidden        = Textstyle{8, 28}
+	Strikethrough = Textstyle{9, 29}
+	Overline      = Textstyle{53, 55}
+
+	// Background colors, for badges such as Styled(BgRed, White).ApplyTo(" FAIL ")
+	BgBlack   = Background(Black)
+	BgRed     = Background(Red)
+	BgGreen   = Background(Green)
+	BgYellow  = Background(Yellow)
+	BgBlue    = Background(Blue)
+	BgMagenta = Background(Magenta)
+	BgCyan    = Background(Cyan)
+	BgWhite   = Background(White)
+	BgDefault = Background(Default)
 )
 
 // Background returns a style that sets the background to the appropriate color
@@ -85,40 +135,134 @@ func Background(c Color) Color {
 // Styled contructs a composite style from one of more color or textstyle values.  Styles
 // can be applied to a string via ApplyTo or as a shortcut use SStyled which returns a string directly
 // Example:  Styled(White, Underline)
+//
+// The style keeps the colors and textstyles as given.  When it is written to a
+// terminal through clt, colors the terminal can't show are replaced by the nearest
+// it has and textstyles it can't show by their fallbacks, see ColorProfile.
 func Styled(s ...Styler) *Style {
-	switch {
-	case len(s) == 1:
-		bef, aft := s[0].Codes()
-		var computedStyle Style
-		computedStyle.before = fmt.Sprintf("\x1b[%vm", bef)
-		computedStyle.after = fmt.Sprintf("\x1b[%vm", aft)
-		return &computedStyle
-	case len(s) > 1:
-		var computedStyle Style
-		var beforeConcat, afterConcat bytes.Buffer
-
-		beforeConcat.WriteString("\x1b[")
-		afterConcat.WriteString("\x1b[")
-
-		var bef, aft int
-		for idx, sty := range s {
-			bef, aft = sty.Codes()
-			if idx < len(s)-1 {
-				beforeConcat.WriteString(fmt.Sprintf("%v;", bef))
-				afterConcat.WriteString(fmt.Sprintf("%v;", aft))
-			} else {
-				beforeConcat.WriteString(fmt.Sprintf("%vm", bef))
-				afterConcat.WriteString(fmt.Sprintf("%vm", aft))
-			}
+	if len(s) == 0 {
+		return &Style{}
+	}
+	before := make([]string, 0, len(s))
+	after := make([]string, 0, len(s))
+	for _, sty := range s {
+		bef, aft := sty.Codes()
+		code := strconv.Itoa(bef)
+		if c, ok := sty.(Color); ok && len(c.extended) > 0 {
+			code += ";" + c.extended
 		}
-		computedStyle.before = beforeConcat.String()
-		computedStyle.after = afterConcat.String()
-		return &computedStyle
+		before, after = append(before, code), append(after, strconv.Itoa(aft))
+	}
+	return &Style{
+		before: "\x1b[" + strings.Join(before, ";") + "m",
+		after:  "\x1b[" + strings.Join(after, ";") + "m",
 	}
-	return &Style{}
 }
 
 // SStyled is a shorter version of Styled(s...).ApplyTo(content)
 func SStyled(content string, s ...Styler) string {
 	return Styled(s...).ApplyTo(content)
 }
+
+// colorConfig records whether styles are applied.  It is detected the first time a style
+// is applied unless set with ForceColor.
+var colorConfig = struct {
+	sync.Mutex
+	set     bool
+	enabled bool
+}{}
+
+// ForceColor turns styles on or off regardless of the environment.  By default, styles
+// are left out when NO_COLOR is set, TERM is dumb, CLICOLOR is 0 or standard output
+// isn't a terminal, unless CLICOLOR_FORCE is set to anything but 0.
+func ForceColor(enabled bool) {
+	colorConfig.Lock()
+	defer colorConfig.Unlock()
+	colorConfig.set = true
+	colorConfig.enabled = enabled
+}
+
+func colorEnabled() bool {
+	colorConfig.Lock()
+	defer colorConfig.Unlock()
+	if !colorConfig.set {
+		colorConfig.set = true
+		colorConfig.enabled = detectColor(os.Getenv, isTerminal(os.Stdout))
+	}
+	return colorConfig.enabled
+}
+
+// detectColor returns whether to use color in an environment looked up with getenv.
+// The conventions at no-color.org and bixense.com/clicolors are followed.
+func detectColor(getenv func(string) string, tty bool) bool {
+	switch {
+	case len(getenv("NO_COLOR")) > 0:
+		return false
+	case len(getenv("CLICOLOR_FORCE")) > 0 && getenv("CLICOLOR_FORCE") != "0":
+		return true
+	case getenv("CLICOLOR") == "0", getenv("TERM") == "dumb":
+		return false
+	}
+	return tty
+}
+
+// StyleBuilder composes colors and textstyles one at a time, as in
+// NewStyle().Bold().Fg(Cyan).Sprint("text").  Each method returns a new builder, so
+// a partly built style can be shared and extended.
+type StyleBuilder struct {
+	stylers []Styler
+}
+
+// NewStyle returns a builder with no styles applied
+func NewStyle() StyleBuilder {
+	return StyleBuilder{}
+}
+
+// With adds any colors or textstyles to the style
+func (b StyleBuilder) With(s ...Styler) StyleBuilder {
+	stylers := make([]Styler, 0, len(b.stylers)+len(s))
+	stylers = append(stylers, b.stylers...)
+	return StyleBuilder{stylers: append(stylers, s...)}
+}
+
+// Bold adds bold text to the style
+func (b StyleBuilder) Bold() StyleBuilder { return b.With(Bold) }
+
+// Dim adds dim text to the style
+func (b StyleBuilder) Dim() StyleBuilder { return b.With(Dim) }
+
+// Italic adds italic text to the style
+func (b StyleBuilder) Italic() StyleBuilder { return b.With(Italic) }
+
+// Underline adds underlined text to the style
+func (b StyleBuilder) Underline() StyleBuilder { return b.With(Underline) }
+
+// Strikethrough adds text crossed out with a line to the style
+func (b StyleBuilder) Strikethrough() StyleBuilder { return b.With(Strikethrough) }
+
+// Blink adds blinking text to the style
+func (b StyleBuilder) Blink() StyleBuilder { return b.With(Blink) }
+
+// Reverse swaps the colors of the text and background in the style
+func (b StyleBuilder) Reverse() StyleBuilder { return b.With(Reverse) }
+
+// Fg sets the color of the text
+func (b StyleBuilder) Fg(c Color) StyleBuilder { return b.With(c) }
+
+// Bg sets the color of the background
+func (b StyleBuilder) Bg(c Color) StyleBuilder { return b.With(Background(c)) }
+
+// Style returns the composed style
+func (b StyleBuilder) Style() *Style {
+	return Styled(b.stylers...)
+}
+
+// Sprint formats its arguments like fmt.Sprint and applies the style
+func (b StyleBuilder) Sprint(a ...interface{}) string {
+	return b.Style().ApplyTo(fmt.Sprint(a...))
+}
+
+// Sprintf formats its arguments like fmt.Sprintf and applies the style
+func (b StyleBuilder) Sprintf(format string, a ...interface{}) string {
+	return b.Style().ApplyTo(fmt.Sprintf(format, a...))
+}
@@ -1,6 +1,10 @@
 package clt
 
-import "testing"
+import (
+	"bytes"
+	"io"
+	"testing"
+)
 
 func TestStyle1(t *testing.T) {
 	s := Styled(Red)
@@ -39,3 +43,129 @@ func TestApplyTo2(t *testing.T) {
 		t.Errorf("Expected: %v\nGot: %v\n", expect, applyResult)
 	}
 }
+
+func TestExtendedColors(t *testing.T) {
+	// styles keep the colors as given whatever the terminal shows
+	t.Setenv("COLORTERM", "")
+	t.Setenv("TERM", "xterm")
+	tt := []struct {
+		Name   string
+		Style  *Style
+		Before string
+		After  string
+	}{
+		{Name: "256", Style: Styled(Color256(208)), Before: "\x1b[38;5;208m", After: "\x1b[39m"},
+		{Name: "rgb", Style: Styled(RGB(255, 128, 0)), Before: "\x1b[38;2;255;128;0m", After: "\x1b[39m"},
+		{Name: "background", Style: Styled(Background(Color256(17))), Before: "\x1b[48;5;17m", After: "\x1b[49m"},
+		{Name: "combined", Style: Styled(RGB(1, 2, 3), Background(RGB(4, 5, 6)), Bold), Before: "\x1b[38;2;1;2;3;48;2;4;5;6;1m", After: "\x1b[39;49;22m"},
+	}
+	for _, tc := range tt {
+		t.Run(tc.Name, func(t *testing.T) {
+			if tc.Style.before != tc.Before || tc.Style.after != tc.After {
+				t.Errorf("Expected before %q after %q, got before %q after %q", tc.Before, tc.After, tc.Style.before, tc.Style.after)
+			}
+		})
+	}
+}
+
+func TestDetectColor(t *testing.T) {
+	tt := []struct {
+		Name string
+		Env  map[string]string
+		TTY  bool
+		Want bool
+	}{
+		{Name: "terminal", TTY: true, Want: true},
+		{Name: "pipe", Want: false},
+		{Name: "no color", Env: map[string]string{"NO_COLOR": "1"}, TTY: true, Want: false},
+		{Name: "no color beats force", Env: map[string]string{"NO_COLOR": "1", "CLICOLOR_FORCE": "1"}, TTY: true, Want: false},
+		{Name: "force on pipe", Env: map[string]string{"CLICOLOR_FORCE": "1"}, Want: true},
+		{Name: "force 0", Env: map[string]string{"CLICOLOR_FORCE": "0"}, Want: false},
+		{Name: "clicolor 0", Env: map[string]string{"CLICOLOR": "0"}, TTY: true, Want: false},
+		{Name: "dumb", Env: map[string]string{"TERM": "dumb"}, TTY: true, Want: false},
+		{Name: "force dumb", Env: map[string]string{"TERM": "dumb", "CLICOLOR_FORCE": "1"}, Want: true},
+	}
+	for _, tc := range tt {
+		t.Run(tc.Name, func(t *testing.T) {
+			getenv := func(key string) string { return tc.Env[key] }
+			if got := detectColor(getenv, tc.TTY); got != tc.Want {
+				t.Errorf("Expected %v, got %v", tc.Want, got)
+			}
+		})
+	}
+}
+
+func TestForceColor(t *testing.T) {
+	defer ForceColor(true)
+	ForceColor(false)
+	if got := SStyled("plain", Red, Bold); got != "plain" {
+		t.Errorf("Expected unstyled text, got %q", got)
+	}
+}
+
+func TestStyleBuilder(t *testing.T) {
+	base := NewStyle().Bold()
+	tt := []struct {
+		Name   string
+		Result string
+		Expect string
+	}{
+		{Name: "chained", Result: NewStyle().Bold().Underline().Fg(Cyan).Bg(Black).Sprint("text"), Expect: "\x1b[1;4;36;40mtext\x1b[22;24;39;49m"},
+		{Name: "sprintf", Result: NewStyle().Italic().Sprintf("%d files", 3), Expect: "\x1b[3m3 files\x1b[23m"},
+		{Name: "shared base", Result: base.Fg(Red).Sprint("a") + base.Sprint("b"), Expect: "\x1b[1;31ma\x1b[22;39m\x1b[1mb\x1b[22m"},
+		{Name: "empty", Result: NewStyle().Sprint("plain"), Expect: "plain"},
+	}
+	for _, tc := range tt {
+		t.Run(tc.Name, func(t *testing.T) {
+			if tc.Result != tc.Expect {
+				t.Errorf("Expected: %q\nGot: %q\n", tc.Expect, tc.Result)
+			}
+		})
+	}
+}
+
+func TestBackgroundColors(t *testing.T) {
+	expect := "\x1b[41;37m FAIL \x1b[49;39m"
+	if got := SStyled(" FAIL ", BgRed, White); got != expect {
+		t.Errorf("Expected: %q\nGot: %q\n", expect, got)
+	}
+	if BgDefault != Background(Default) {
+		t.Errorf("Expected BgDefault to reset the background")
+	}
+}
+
+func TestTextstyleFallback(t *testing.T) {
+	tt := []struct {
+		Name string
+		Env  map[string]string
+		Want string
+	}{
+		{Name: "xterm", Env: map[string]string{"TERM": "xterm-256color"}, Want: "\x1b[3;9;53mx\x1b[0m"},
+		{Name: "linux console", Env: map[string]string{"TERM": "linux"}, Want: "\x1b[2;4mx\x1b[0m"},
+		{Name: "screen", Env: map[string]string{"TERM": "screen-256color"}, Want: "\x1b[4;9mx\x1b[0m"},
+		{Name: "terminal.app", Env: map[string]string{"TERM": "xterm-256color", "TERM_PROGRAM": "Apple_Terminal"}, Want: "\x1b[2;3mx\x1b[0m"},
+	}
+	for _, tc := range tt {
+		t.Run(tc.Name, func(t *testing.T) {
+			var out bytes.Buffer
+			w := &styleWriter{w: &out, depth: TrueColor, attrs: detectAttributes(func(key string) string { return tc.Env[key] }, true)}
+			io.WriteString(w, Styled(Italic, Strikethrough, Overline).ApplyTo("x")+"\x1b[0m")
+			if got := out.String(); got != tc.Want {
+				t.Errorf("Expected %q, got %q", tc.Want, got)
+			}
+		})
+	}
+	t.Setenv("TERM", "linux")
+	if s := Styled(Overline); s.before != "\x1b[53m" {
+		t.Errorf("Expected the style to keep an unsupported textstyle until it is written, got %q", s.before)
+	}
+}
+
+func TestColorEqual(t *testing.T) {
+	if RGB(255, 128, 0) != RGB(255, 128, 0) || Color256(42) != Color256(42) {
+		t.Errorf("Expected colors with the same value to be equal")
+	}
+	if RGB(255, 128, 0) == RGB(255, 128, 1) {
+		t.Errorf("Expected colors with different values not to be equal")
+	}
+}
@@ -0,0 +1,147 @@
+package clt
+
+import (
+	"fmt"
+	"strings"
+)
+
+// subtaskIndent is the indentation of each level of subtasks
+const subtaskIndent = "  "
+
+// Child adds a subtask to the progress indicator that is rendered as an indented
+// spinner line below its parent.  Subtasks are started and terminated like any other
+// progress indicator, but their parent draws them, so the parent must be started for
+// them to be shown.  A parent bar shows the aggregate completion of its subtasks and
+// a parent spinner shows how many have finished.  Subtasks can have their own subtasks.
+func (p *Progress) Child(format string, args ...interface{}) *Progress {
+	c := NewProgressSpinner(format, args...)
+	p.addChild(c)
+	return c
+}
+
+// ChildBar adds a subtask that is rendered as an indented progress bar below its parent
+func (p *Progress) ChildBar(format string, args ...interface{}) *Progress {
+	c := NewProgressBar(format, args...)
+	c.AutoSize = false
+	p.addChild(c)
+	return c
+}
+
+func (p *Progress) addChild(c *Progress) {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	c.parent = p
+	c.output = p.output
+	c.tty = p.tty
+	c.json = p.json
+	c.spinsteps = p.spinsteps
+	c.SpinnerStyle, c.PromptStyle = p.SpinnerStyle, p.PromptStyle
+	if len(c.spinsteps) == 0 {
+		c.spinsteps = Wheel
+	}
+	p.children = append(p.children, c)
+}
+
+func (p *Progress) hasChildren() bool {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	return len(p.children) > 0
+}
+
+// refresh redraws the root of a tree of subtasks after one of them changes.
+// Spinners redraw on their next frame so only bars need to be told.
+func (p *Progress) refresh() {
+	root := p
+	for root.parent != nil {
+		root = root.parent
+	}
+	if root.style == bar {
+		root.update(nil)
+	}
+}
+
+// aggregate returns the fraction of work completed by all subtasks.  Finished
+// subtasks count as complete, bars count their own progress, and spinners count
+// nothing until they finish.  Must be called with the mutex held.
+func (p *Progress) aggregate() float64 {
+	if len(p.children) == 0 {
+		return p.pct
+	}
+	var sum float64
+	for _, c := range p.children {
+		c.mtx.Lock()
+		switch {
+		case c.state == finished:
+			sum += 1.0
+		case c.state == running && (c.style == bar || len(c.children) > 0):
+			sum += c.aggregate()
+		}
+		c.mtx.Unlock()
+	}
+	return sum / float64(len(p.children))
+}
+
+// childCounts returns the number of finished subtasks and the total number of
+// subtasks.  Must be called with the mutex held.
+func (p *Progress) childCounts() (done int, total int) {
+	for _, c := range p.children {
+		c.mtx.Lock()
+		if c.state == finished {
+			done++
+		}
+		c.mtx.Unlock()
+	}
+	return done, len(p.children)
+}
+
+// multiline returns true if the indicator is drawn on more than one line because it
+// has subtasks or there is a context line.  Must be called with the mutex held.
+func (p *Progress) multiline() bool {
+	return len(p.children) > 0 || len(ContextLine()) > 0
+}
+
+// tree returns line followed by the lines of all subtasks, replacing the tree drawn
+// by the previous call.  The context line, if set, is drawn first.  Must be called
+// with the mutex held.
+func (p *Progress) tree(line string, frame int) string {
+	lines := append([]string{line}, p.childLines(frame, subtaskIndent)...)
+	if ctx := ContextLine(); len(ctx) > 0 {
+		lines = append([]string{ctx}, lines...)
+	}
+
+	out := composeFrame(p.lines, lines)
+	p.lines = len(lines)
+	return out
+}
+
+// childLines renders every subtask and their subtasks at the given indent.  Must be
+// called with the mutex held.
+func (p *Progress) childLines(frame int, indent string) []string {
+	var lines []string
+	for _, c := range p.children {
+		c.mtx.Lock()
+		lines = append(lines, indent+c.subtaskLine(frame))
+		lines = append(lines, c.childLines(frame, indent+subtaskIndent)...)
+		c.mtx.Unlock()
+	}
+	return lines
+}
+
+// subtaskLine renders a subtask in its current state.  Must be called with the
+// mutex held.
+func (p *Progress) subtaskLine(frame int) string {
+	s := p.frameState()
+	switch {
+	case s.state == idle:
+		return fmt.Sprintf("%s[%s]", s.prompt, strings.Repeat(" ", displayWidth(spinLookup(0, p.spinsteps))))
+	case p.style == bar && s.state == running:
+		s.pct = p.aggregate()
+		return p.barLine(s)
+	case p.style == bar:
+		return p.barLine(s)
+	case s.state == finished:
+		msg, sty := s.outcome()
+		return fmt.Sprintf("%s[%s]%s", s.prompt, sty.ApplyTo(msg), s.tally()+p.suffix())
+	}
+	return fmt.Sprintf("%s[%s]%s", p.stylePrompt(s.prompt), p.styleSpinner(spinLookup(frame, p.spinsteps)), s.tally()+s.statusSuffix()+p.suffix())
+}
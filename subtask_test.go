@@ -0,0 +1,52 @@
+package clt
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/BTBurke/snapshot"
+)
+
+func TestSubtasks(t *testing.T) {
+	out := bytes.NewBuffer(nil)
+
+	p := NewProgressBar("Install")
+	p.output = out
+	p.Interval = time.Hour
+	fetch := p.ChildBar("fetch")
+	build := p.Child("build")
+	p.Start()
+	fetch.Start()
+	fetch.Update(0.5)
+	fetch.Success()
+	build.Start()
+	build.Fail()
+	p.Fail()
+
+	// intermediate frames depend on when the renderer runs, so only the
+	// final tree is compared
+	frames := strings.Split(out.String(), "\x1b[?25l")
+	snapshot.Assert(t, []byte(frames[len(frames)-1]))
+}
+
+func TestSubtaskAggregate(t *testing.T) {
+	p := NewProgressBar("Parent")
+	p.output = bytes.NewBuffer(nil)
+	a := p.ChildBar("a")
+	b := p.Child("b")
+	p.Start()
+	a.Start()
+	a.Update(0.5)
+	b.Start()
+	b.Success()
+
+	p.mtx.Lock()
+	got := p.aggregate()
+	p.mtx.Unlock()
+	if got != 0.75 {
+		t.Errorf("Expected aggregate completion 0.75, got %v", got)
+	}
+	p.Success()
+}
@@ -0,0 +1,46 @@
+package clt
+
+import (
+	"fmt"
+	"text/template"
+)
+
+// TemplateFuncs returns functions for styling text in a text/template, such as for a
+// help screen or report:
+//
+//	{{ .Name | bold }} {{ red "failed" }} {{ .Status | success }}
+//	{{ style "bold,cyan" .Title }} {{ .Summary | wrap 72 | indent "  " }}
+//
+// Every style that markup tags can name is a function of the same name, including the
+// styles of the theme, which are looked up when the template is executed so that
+// SetTheme applies to templates parsed before it.  style takes the same comma
+// separated names as a markup tag.  wrap, indent, truncate, strip and link call Wrap,
+// Indent, Truncate, Strip and Link, with the text last so that they can be piped to.
+func TemplateFuncs() template.FuncMap {
+	funcs := template.FuncMap{
+		"style": func(names string, a ...interface{}) (string, error) {
+			sty, ok := markupStyle(names)
+			if !ok {
+				return "", fmt.Errorf("unknown style %q", names)
+			}
+			return sty.ApplyTo(fmt.Sprint(a...)), nil
+		},
+		"wrap":     func(width int, s string) string { return Wrap(s, width) },
+		"indent":   func(prefix string, s string) string { return Indent(s, prefix) },
+		"truncate": func(n int, s string) string { return Truncate(s, n) },
+		"strip":    Strip,
+		"link":     func(url string, text string) string { return Link(text, url) },
+	}
+	names := append([]string(nil), themeStyleNames...)
+	for name := range markupStyles {
+		names = append(names, name)
+	}
+	for _, name := range names {
+		name := name
+		funcs[name] = func(a ...interface{}) string {
+			sty, _ := markupStyle(name)
+			return sty.ApplyTo(fmt.Sprint(a...))
+		}
+	}
+	return funcs
+}
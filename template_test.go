@@ -0,0 +1,37 @@
+package clt
+
+import (
+	"strings"
+	"testing"
+	"text/template"
+)
+
+func TestTemplateFuncs(t *testing.T) {
+	defer SetTheme(DefaultTheme)
+	tmpl := template.Must(template.New("help").Funcs(TemplateFuncs()).Parse(
+		`{{ .Name | bold }} {{ red "x" }} {{ style "bold,cyan" .Name }} {{ .Name | success }}|{{ "one two" | wrap 3 | indent "  " }}`))
+	SetTheme(Theme{Success: Styled(Magenta)})
+	var out strings.Builder
+	if err := tmpl.Execute(&out, struct{ Name string }{"clt"}); err != nil {
+		t.Fatal(err)
+	}
+	want := "\x1b[1mclt\x1b[22m \x1b[31mx\x1b[39m \x1b[1m\x1b[36mclt\x1b[39m\x1b[22m \x1b[35mclt\x1b[39m|  one\n  two"
+	if out.String() != want {
+		t.Errorf("Expected %q, got %q", want, out.String())
+	}
+
+	ForceHyperlinks(false)
+	link := template.Must(template.New("link").Funcs(TemplateFuncs()).Parse(`{{ "docs" | link "https://example.com" }}`))
+	out.Reset()
+	if err := link.Execute(&out, nil); err != nil {
+		t.Fatal(err)
+	}
+	if want := "docs (https://example.com)"; out.String() != want {
+		t.Errorf("Expected %q, got %q", want, out.String())
+	}
+
+	bad := template.Must(template.New("bad").Funcs(TemplateFuncs()).Parse(`{{ style "sparkly" "x" }}`))
+	if err := bad.Execute(&out, nil); err == nil {
+		t.Errorf("Expected an unknown style to fail the template")
+	}
+}
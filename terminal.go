@@ -0,0 +1,69 @@
+package clt
+
+import (
+	"io"
+	"os"
+	"reflect"
+
+	"golang.org/x/crypto/ssh/terminal"
+)
+
+// underlyingOutput returns the writer wrapped by consoleOutput
+func underlyingOutput(w io.Writer) io.Writer {
+	switch o := w.(type) {
+	case *plainWriter:
+		return o.w
+	case *styleWriter:
+		return o.w
+	}
+	return w
+}
+
+// sameOutput returns true if a and b write to the same place
+func sameOutput(a io.Writer, b io.Writer) bool {
+	a, b = underlyingOutput(a), underlyingOutput(b)
+	if a == nil || reflect.TypeOf(a) != reflect.TypeOf(b) || !reflect.TypeOf(a).Comparable() {
+		return false
+	}
+	return a == b
+}
+
+// isTerminal returns true if w is connected to a terminal
+func isTerminal(w io.Writer) bool {
+	w = underlyingOutput(w)
+	f, ok := w.(*os.File)
+	return ok && terminal.IsTerminal(int(f.Fd()))
+}
+
+// terminalWidth returns the width in columns of the terminal connected to w,
+// or 0 if w is not a terminal or its size can't be determined.
+func terminalWidth(w io.Writer) int {
+	w = underlyingOutput(w)
+	if c, ok := w.(*capabilityWriter); ok {
+		return c.caps.Width
+	}
+	f, ok := w.(*os.File)
+	if !ok || !terminal.IsTerminal(int(f.Fd())) {
+		return 0
+	}
+	width, _, err := terminal.GetSize(int(f.Fd()))
+	if err != nil || width <= 0 {
+		return 0
+	}
+	return width
+}
+
+// terminalHeight returns the height in lines of the terminal connected to w,
+// or 0 if w is not a terminal or its size can't be determined.
+func terminalHeight(w io.Writer) int {
+	w = underlyingOutput(w)
+	f, ok := w.(*os.File)
+	if !ok || !terminal.IsTerminal(int(f.Fd())) {
+		return 0
+	}
+	_, height, err := terminal.GetSize(int(f.Fd()))
+	if err != nil || height <= 0 {
+		return 0
+	}
+	return height
+}
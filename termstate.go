@@ -0,0 +1,138 @@
+package clt
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// terminalState records what clt has changed about the terminal, so that Repair can
+// undo it if the program crashes before restoring it
+type terminalState struct {
+	// Mode is the original terminal mode saved before entering raw mode
+	Mode []byte `json:"mode,omitempty"`
+	// CursorHidden is set while progress indicators have hidden the cursor
+	CursorHidden bool `json:"cursor_hidden,omitempty"`
+	// ScrollRegion is set while a pinned indicator limits scrolling to part of the
+	// terminal
+	ScrollRegion bool `json:"scroll_region,omitempty"`
+}
+
+// termState keeps the saved state in a file in the temporary directory so that it
+// survives the process that changed the terminal.  The file is named after the user
+// and the terminal, see terminalKey, so that programs in other terminals don't
+// overwrite or repair each other's state.
+var termState = struct {
+	sync.Mutex
+	path   string
+	output io.Writer
+	saved  terminalState
+}{
+	path:   filepath.Join(os.TempDir(), "clt-terminal-"+terminalKey()+".json"),
+	output: os.Stdout,
+}
+
+// processKey is the key of the state file when the terminal can't be identified, so
+// that the state is never shared, although it can't be repaired by a later run
+func processKey() string {
+	return fmt.Sprintf("pid%d", os.Getpid())
+}
+
+// saveTerminalState applies f to the recorded state and writes it to the state file.
+// The file is removed once nothing needs to be repaired.
+func saveTerminalState(f func(s *terminalState)) {
+	termState.Lock()
+	defer termState.Unlock()
+	s := termState.saved
+	f(&s)
+	old := termState.saved
+	if s.CursorHidden == old.CursorHidden && s.ScrollRegion == old.ScrollRegion && string(s.Mode) == string(old.Mode) {
+		return
+	}
+	termState.saved = s
+	if len(s.Mode) == 0 && !s.CursorHidden && !s.ScrollRegion {
+		os.Remove(termState.path)
+		return
+	}
+	data, err := json.Marshal(s)
+	if err != nil {
+		return
+	}
+	os.WriteFile(termState.path, data, 0600)
+}
+
+// enterRawMode saves the mode of the terminal fd before it is changed.  A mode left
+// over from a crash is kept, since it is the one the terminal should go back to.
+func enterRawMode(fd int) {
+	mode, err := getTerminalMode(fd)
+	if err != nil {
+		return
+	}
+	if old, err := loadTerminalState(); err == nil && len(old.Mode) > 0 {
+		mode = old.Mode
+	}
+	saveTerminalState(func(s *terminalState) { s.Mode = mode })
+}
+
+// leaveRawMode forgets the saved mode once the terminal has been restored
+func leaveRawMode() {
+	saveTerminalState(func(s *terminalState) { s.Mode = nil })
+}
+
+// setCursorHidden records whether the cursor is hidden
+func setCursorHidden(hidden bool) {
+	saveTerminalState(func(s *terminalState) { s.CursorHidden = hidden })
+}
+
+// setScrollRegion records whether a scroll region is set
+func setScrollRegion(set bool) {
+	saveTerminalState(func(s *terminalState) { s.ScrollRegion = set })
+}
+
+func loadTerminalState() (terminalState, error) {
+	var s terminalState
+	termState.Lock()
+	path := termState.path
+	termState.Unlock()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return s, err
+	}
+	err = json.Unmarshal(data, &s)
+	return s, err
+}
+
+// Repair restores a terminal left in a broken state by a previous run that crashed,
+// such as one without echo or with a hidden cursor.  Unlike reset, it only undoes
+// what clt changed, and it does nothing if the last run exited cleanly.  Call it
+// early in main, or from a repair command.
+func Repair() error {
+	s, err := loadTerminalState()
+	switch {
+	case os.IsNotExist(err):
+		return nil
+	case err != nil:
+		return err
+	}
+	if len(s.Mode) > 0 {
+		if err := setTerminalMode(int(os.Stdin.Fd()), s.Mode); err != nil {
+			return err
+		}
+	}
+	termState.Lock()
+	defer termState.Unlock()
+	if s.CursorHidden {
+		fmt.Fprint(termState.output, "\x1b[?25h")
+	}
+	if s.ScrollRegion {
+		fmt.Fprint(termState.output, "\x1b[r")
+	}
+	termState.saved = terminalState{}
+	if err := os.Remove(termState.path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,38 @@
+//go:build !linux && !darwin && !dragonfly && !freebsd && !netbsd && !openbsd && !windows
+
+package clt
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+)
+
+var errTerminalMode = errors.New("saving the terminal mode is not supported on this platform")
+
+func getTerminalMode(fd int) ([]byte, error) {
+	return nil, errTerminalMode
+}
+
+func setTerminalMode(fd int, mode []byte) error {
+	return errTerminalMode
+}
+
+func terminalKey() string {
+	return fmt.Sprintf("%d-%s", os.Getuid(), processKey())
+}
+
+func setCbreak(fd int) (func(), error) {
+	return nil, errTerminalMode
+}
+
+func keyPressed(fd int, timeout time.Duration) bool {
+	time.Sleep(timeout)
+	return false
+}
+
+func readKeys(fd int, timeout time.Duration) []byte {
+	time.Sleep(timeout)
+	return nil
+}
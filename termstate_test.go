@@ -0,0 +1,79 @@
+package clt
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func withTerminalState(t *testing.T) *bytes.Buffer {
+	out := bytes.NewBuffer(nil)
+	termState.Lock()
+	path, output := termState.path, termState.output
+	termState.path, termState.output = filepath.Join(t.TempDir(), "terminal.json"), out
+	termState.Unlock()
+	t.Cleanup(func() {
+		termState.Lock()
+		termState.path, termState.output, termState.saved = path, output, terminalState{}
+		termState.Unlock()
+	})
+	return out
+}
+
+func TestRepair(t *testing.T) {
+	out := withTerminalState(t)
+
+	if err := Repair(); err != nil || out.Len() > 0 {
+		t.Errorf("Expected nothing to repair after a clean exit, got %v %q", err, out.String())
+	}
+
+	// a crash while the cursor is hidden leaves the state file behind
+	setCursorHidden(true)
+	if _, err := os.Stat(termState.path); err != nil {
+		t.Fatalf("Expected the state file to be written: %s", err)
+	}
+	if err := Repair(); err != nil {
+		t.Fatal(err)
+	}
+	if out.String() != "\x1b[?25h" {
+		t.Errorf("Expected the cursor to be shown, got %q", out.String())
+	}
+	if _, err := os.Stat(termState.path); !os.IsNotExist(err) {
+		t.Errorf("Expected Repair to remove the state file")
+	}
+}
+
+func TestTerminalStateCleared(t *testing.T) {
+	withTerminalState(t)
+
+	setCursorHidden(true)
+	setCursorHidden(false)
+	if _, err := os.Stat(termState.path); !os.IsNotExist(err) {
+		t.Errorf("Expected the state file to be removed once the cursor is restored")
+	}
+}
+
+func TestRepairScrollRegion(t *testing.T) {
+	out := withTerminalState(t)
+
+	setScrollRegion(true)
+	if err := Repair(); err != nil {
+		t.Fatal(err)
+	}
+	if out.String() != "\x1b[r" {
+		t.Errorf("Expected the scroll region to be reset, got %q", out.String())
+	}
+}
+
+func TestTerminalKey(t *testing.T) {
+	key := terminalKey()
+	if isTerminal(os.Stdin) {
+		t.Skip("stdin is a terminal")
+	}
+	// without a terminal to name, the state is kept per process
+	if !strings.HasSuffix(key, processKey()) {
+		t.Errorf("Expected the key to name the process when stdin isn't a terminal, got %q", key)
+	}
+}
@@ -0,0 +1,91 @@
+//go:build linux || darwin || dragonfly || freebsd || netbsd || openbsd
+
+package clt
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"os"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// getTerminalMode returns the termios settings of fd encoded for the state file
+func getTerminalMode(fd int) ([]byte, error) {
+	t, err := unix.IoctlGetTermios(fd, ioctlReadTermios)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := binary.Write(&buf, binary.LittleEndian, t); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// setTerminalMode restores termios settings returned by getTerminalMode
+func setTerminalMode(fd int, mode []byte) error {
+	var t unix.Termios
+	if err := binary.Read(bytes.NewReader(mode), binary.LittleEndian, &t); err != nil {
+		return err
+	}
+	return unix.IoctlSetTermios(fd, ioctlWriteTermios, &t)
+}
+
+// terminalKey identifies the user and the terminal on stdin, by its device number, so
+// that a later run in the same terminal finds the state left by one that crashed
+func terminalKey() string {
+	var st unix.Stat_t
+	if _, err := unix.IoctlGetTermios(0, ioctlReadTermios); err != nil {
+		return fmt.Sprintf("%d-%s", os.Getuid(), processKey())
+	}
+	if err := unix.Fstat(0, &st); err != nil {
+		return fmt.Sprintf("%d-%s", os.Getuid(), processKey())
+	}
+	return fmt.Sprintf("%d-tty%d", os.Getuid(), st.Rdev)
+}
+
+// setCbreak turns off echo and line buffering on fd so that single key presses can be
+// read, while leaving signals such as Ctrl-C working.  It returns a function that
+// restores the previous mode.
+func setCbreak(fd int) (func(), error) {
+	old, err := unix.IoctlGetTermios(fd, ioctlReadTermios)
+	if err != nil {
+		return nil, err
+	}
+	enterRawMode(fd)
+	t := *old
+	t.Lflag &^= unix.ECHO | unix.ICANON
+	t.Cc[unix.VMIN], t.Cc[unix.VTIME] = 1, 0
+	if err := unix.IoctlSetTermios(fd, ioctlWriteTermios, &t); err != nil {
+		leaveRawMode()
+		return nil, err
+	}
+	return func() {
+		unix.IoctlSetTermios(fd, ioctlWriteTermios, old)
+		leaveRawMode()
+	}, nil
+}
+
+// keyPressed waits up to timeout for a key press on fd and consumes it
+func keyPressed(fd int, timeout time.Duration) bool {
+	return len(readKeys(fd, timeout)) > 0
+}
+
+// readKeys waits up to timeout for key presses on fd and returns what they sent, or
+// nothing if no key was pressed
+func readKeys(fd int, timeout time.Duration) []byte {
+	fds := []unix.PollFd{{Fd: int32(fd), Events: unix.POLLIN}}
+	n, err := unix.Poll(fds, int(timeout/time.Millisecond))
+	if err != nil || n == 0 {
+		return nil
+	}
+	b := make([]byte, 16)
+	n, err = unix.Read(fd, b)
+	if err != nil || n <= 0 {
+		return nil
+	}
+	return b[:n]
+}
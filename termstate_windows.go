@@ -0,0 +1,68 @@
+//go:build windows
+
+package clt
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// getTerminalMode returns the console mode of fd encoded for the state file
+func getTerminalMode(fd int) ([]byte, error) {
+	var mode uint32
+	if err := syscall.GetConsoleMode(syscall.Handle(fd), &mode); err != nil {
+		return nil, err
+	}
+	b := make([]byte, 4)
+	binary.LittleEndian.PutUint32(b, mode)
+	return b, nil
+}
+
+// setTerminalMode restores a console mode returned by getTerminalMode
+func setTerminalMode(fd int, mode []byte) error {
+	if len(mode) != 4 {
+		return errors.New("invalid console mode")
+	}
+	if r, _, err := setConsoleMode.Call(uintptr(fd), uintptr(binary.LittleEndian.Uint32(mode))); r == 0 {
+		return err
+	}
+	return nil
+}
+
+// terminalKey identifies the user and the console by the process that started the
+// program, usually the shell running in the console, since Windows has no user IDs
+// like Unix and no terminal device to name
+func terminalKey() string {
+	return fmt.Sprintf("%s-ppid%d", sanitizeKey(os.Getenv("USERNAME")), os.Getppid())
+}
+
+// sanitizeKey keeps only the characters of s that are safe in a file name
+func sanitizeKey(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_':
+			return r
+		}
+		return -1
+	}, s)
+}
+
+// setCbreak is not supported on Windows consoles, so key presses aren't detected
+func setCbreak(fd int) (func(), error) {
+	return nil, errors.New("reading single keys is not supported on Windows")
+}
+
+func keyPressed(fd int, timeout time.Duration) bool {
+	time.Sleep(timeout)
+	return false
+}
+
+func readKeys(fd int, timeout time.Duration) []byte {
+	time.Sleep(timeout)
+	return nil
+}
@@ -0,0 +1,139 @@
+package clt
+
+import (
+	"fmt"
+	"sync"
+)
+
+// Theme maps the meaning of a piece of output to the style it is shown in, so that
+// the colors of every widget can be changed in one place
+type Theme struct {
+	// Success styles results that succeeded, such as OK and the filled part of a
+	// completed bar
+	Success *Style
+	// Error styles failures and error messages
+	Error *Style
+	// Warning styles warnings and changes that need attention
+	Warning *Style
+	// Info styles tips and highlighted values
+	Info *Style
+	// Muted styles secondary text such as hints
+	Muted *Style
+	// Prompt styles the prompts of questions and progress indicators
+	Prompt *Style
+	// Reminder styles a prompt that has waited too long for an answer, see
+	// WithIdleReminder
+	Reminder *Style
+}
+
+// DefaultTheme is the theme used until SetTheme is called.  Prompts are unstyled.
+var DefaultTheme = Theme{
+	Success:  Styled(Green),
+	Error:    Styled(Red),
+	Warning:  Styled(Yellow),
+	Info:     Styled(Cyan),
+	Muted:    Styled(Dim),
+	Prompt:   &Style{},
+	Reminder: Styled(Yellow, Bold),
+}
+
+// themeConfig is the theme set with SetTheme
+var themeConfig = struct {
+	sync.RWMutex
+	theme Theme
+}{theme: DefaultTheme}
+
+// SetTheme sets the styles used by progress indicators, tables and prompts.  Styles
+// left nil are taken from DefaultTheme, so a theme can change just some of them.
+func SetTheme(t Theme) {
+	def := DefaultTheme
+	for _, s := range []struct{ style, def **Style }{
+		{&t.Success, &def.Success},
+		{&t.Error, &def.Error},
+		{&t.Warning, &def.Warning},
+		{&t.Info, &def.Info},
+		{&t.Muted, &def.Muted},
+		{&t.Prompt, &def.Prompt},
+		{&t.Reminder, &def.Reminder},
+	} {
+		if *s.style == nil {
+			*s.style = *s.def
+		}
+	}
+	themeConfig.Lock()
+	defer themeConfig.Unlock()
+	themeConfig.theme = t
+}
+
+// CurrentTheme returns the theme set with SetTheme
+func CurrentTheme() Theme {
+	themeConfig.RLock()
+	defer themeConfig.RUnlock()
+	return themeConfig.theme
+}
+
+// themes are the themes that can be chosen by name with ChooseTheme
+var themes = struct {
+	sync.Mutex
+	t map[string]Theme
+}{t: map[string]Theme{"default": DefaultTheme}}
+
+// RegisterTheme adds a theme that can be chosen by name with ChooseTheme, such as
+// from a --theme flag or a settings screen.  DefaultTheme is registered as default.
+func RegisterTheme(name string, t Theme) {
+	themes.Lock()
+	defer themes.Unlock()
+	themes.t[name] = t
+}
+
+// ChooseTheme sets the theme registered as name and remembers the choice in the
+// Store, so that RestoreTheme sets it again on the next run
+func ChooseTheme(name string) error {
+	themes.Lock()
+	t, ok := themes.t[name]
+	themes.Unlock()
+	if !ok {
+		return fmt.Errorf("unknown theme %q", name)
+	}
+	SetTheme(t)
+	return preferences().Set(themeKey, name)
+}
+
+// RestoreTheme sets the theme last chosen with ChooseTheme and returns its name.  It
+// returns false and leaves the theme unchanged if none was chosen or the one chosen
+// is no longer registered.
+func RestoreTheme() (string, bool) {
+	name, ok := preferences().Get(themeKey)
+	if !ok {
+		return "", false
+	}
+	themes.Lock()
+	t, ok := themes.t[name]
+	themes.Unlock()
+	if !ok {
+		return "", false
+	}
+	SetTheme(t)
+	return name, true
+}
+
+// themeKey is the key of the chosen theme in the Store
+const themeKey = "theme"
+
+// themeStyleNames are the names of the styles of a theme in markup tags and templates
+var themeStyleNames = []string{"success", "error", "warning", "info", "muted", "prompt", "reminder"}
+
+// byName returns the style of the theme named in themeStyleNames
+func (t Theme) byName(name string) (*Style, bool) {
+	sty, ok := map[string]*Style{
+		"success": t.Success, "error": t.Error, "warning": t.Warning, "info": t.Info,
+		"muted": t.Muted, "prompt": t.Prompt, "reminder": t.Reminder,
+	}[name]
+	return sty, ok
+}
+
+// emphasized returns sty from a theme with textstyles such as Bold added to it
+func emphasized(sty *Style, styles ...Styler) *Style {
+	extra := Styled(styles...)
+	return &Style{before: sty.before + extra.before, after: extra.after + sty.after}
+}
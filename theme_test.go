@@ -0,0 +1,52 @@
+package clt
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestSetTheme(t *testing.T) {
+	defer SetTheme(DefaultTheme)
+	SetTheme(Theme{Success: Styled(Blue), Prompt: Styled(Bold)})
+
+	theme := CurrentTheme()
+	if theme.Success.ApplyTo("x") != SStyled("x", Blue) {
+		t.Errorf("Expected the success style to be replaced")
+	}
+	if theme.Error.ApplyTo("x") != SStyled("x", Red) {
+		t.Errorf("Expected styles left out of the theme to use the default")
+	}
+
+	out := new(bytes.Buffer)
+	p := NewProgressSpinner("Deploying", WithProgressOutput(out))
+	p.Start()
+	p.Success()
+	if !strings.Contains(out.String(), SStyled("Deploying", Bold)+"["+SStyled("OK", Blue)+"]") {
+		t.Errorf("Expected the progress indicator to use the theme, got %q", out.String())
+	}
+}
+
+func TestChooseTheme(t *testing.T) {
+	SetStore(&memoryStore{values: make(map[string]string)})
+	defer SetStore(nil)
+	defer SetTheme(DefaultTheme)
+	RegisterTheme("mono", Theme{Success: Styled(Bold)})
+
+	if err := ChooseTheme("nope"); err == nil {
+		t.Errorf("Expected an error for a theme that isn't registered")
+	}
+	if _, ok := RestoreTheme(); ok {
+		t.Errorf("Expected no theme to be restored before one is chosen")
+	}
+	if err := ChooseTheme("mono"); err != nil {
+		t.Fatal(err)
+	}
+	SetTheme(DefaultTheme)
+	if name, ok := RestoreTheme(); !ok || name != "mono" {
+		t.Errorf("Expected the chosen theme to be restored, got %q %v", name, ok)
+	}
+	if got := CurrentTheme().Success.ApplyTo("ok"); got != Styled(Bold).ApplyTo("ok") {
+		t.Errorf("Expected the restored theme to be set, got %q", got)
+	}
+}
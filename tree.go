@@ -0,0 +1,142 @@
+package clt
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// Tree is a node of hierarchical data, such as a file tree or a dependency graph,
+// that is rendered with guides joining each node to its children
+type Tree struct {
+	label    string
+	style    *Style
+	children []*Tree
+	lazy     func() []*Tree
+	// collapsed nodes are shown without their children, and maxDepth limits the
+	// levels of children rendered below the node, or 0 for every level
+	collapsed bool
+	maxDepth  int
+	writer    io.Writer
+}
+
+// NewTree returns the root node of a tree.  The label is unstyled unless styles are
+// given.
+func NewTree(label string, styles ...Styler) *Tree {
+	t := &Tree{label: label, writer: os.Stdout}
+	if len(styles) > 0 {
+		t.style = Styled(styles...)
+	}
+	return t
+}
+
+// Add adds a child node and returns it, so that children can be added to it in turn
+func (t *Tree) Add(label string, styles ...Styler) *Tree {
+	child := NewTree(label, styles...)
+	t.children = append(t.children, child)
+	return child
+}
+
+// AddTree adds trees as children of the node and returns the node
+func (t *Tree) AddTree(children ...*Tree) *Tree {
+	t.children = append(t.children, children...)
+	return t
+}
+
+// Children sets a function that returns the children of the node, which is only
+// called the first time the node is rendered with its children, such as to read a
+// directory or resolve dependencies that may never be shown.  Collapse and MaxDepth
+// keep it from being called.  The children follow any that were added.  A node that
+// is returned again below itself, as in a dependency cycle, is marked as a cycle
+// instead of being expanded again.
+func (t *Tree) Children(f func() []*Tree) *Tree {
+	t.lazy = f
+	return t
+}
+
+// Collapse shows the node without its children
+func (t *Tree) Collapse() *Tree {
+	t.collapsed = true
+	return t
+}
+
+// MaxDepth limits the tree rendered from the node to n levels of children.  Nodes
+// at the last level are shown without their children.  A depth of 0 shows every
+// level.
+func (t *Tree) MaxDepth(n int) *Tree {
+	t.maxDepth = n
+	return t
+}
+
+// nodes returns the children of the node, calling the function set with Children once
+func (t *Tree) nodes() []*Tree {
+	if t.lazy != nil {
+		t.children = append(t.children, t.lazy()...)
+		t.lazy = nil
+	}
+	return t.children
+}
+
+// SetWriter sets the output writer if not writing to Stdout
+func (t *Tree) SetWriter(w io.Writer) {
+	t.writer = w
+}
+
+// Show writes the tree to the configured writer
+func (t *Tree) Show() {
+	fmt.Fprint(consoleOutput(t.writer), t.AsString())
+}
+
+// AsString returns the rendered tree as a string instead of immediately writing to
+// the configured writer.  Labels with line breaks continue under the start of the
+// label.
+func (t *Tree) AsString() string {
+	depth := t.maxDepth
+	if depth == 0 {
+		depth = -1
+	}
+	var b strings.Builder
+	t.render(&b, "", "", depth, make(map[*Tree]bool))
+	return b.String()
+}
+
+// render writes the node after first and its children after rest, which are the
+// guides leading to the node and those continuing past it.  Children are rendered
+// to depth levels, or every level if it is negative, and path holds the nodes above this one so
+// that cycles are shown only once.
+func (t *Tree) render(b *strings.Builder, first string, rest string, depth int, path map[*Tree]bool) {
+	guide := CurrentTheme().Muted
+	label := t.label
+	if path[t] {
+		label += guide.ApplyTo(" (cycle)")
+	}
+	for i, line := range splitLines(label) {
+		if t.style != nil {
+			line = t.style.ApplyTo(line)
+		}
+		switch i {
+		case 0:
+			b.WriteString(first + line + "\n")
+		default:
+			b.WriteString(rest + line + "\n")
+		}
+	}
+	if path[t] || t.collapsed || depth == 0 {
+		return
+	}
+	if depth > 0 {
+		depth--
+	}
+	path[t] = true
+	defer delete(path, t)
+	children := t.nodes()
+	for i, child := range children {
+		switch {
+		case i == len(children)-1:
+			child.render(b, rest+guide.ApplyTo("└── "), rest+"    ", depth, path)
+		default:
+			child.render(b, rest+guide.ApplyTo("├── "), rest+guide.ApplyTo("│   "), depth, path)
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package clt
+
+import (
+	"bytes"
+	"io"
+	"testing"
+)
+
+func TestTree(t *testing.T) {
+	root := NewTree("project")
+	cmd := root.Add("cmd")
+	cmd.Add("main.go")
+	root.Add("README.md")
+	lazyCalls := 0
+	root.Add("vendor").Children(func() []*Tree {
+		lazyCalls++
+		return []*Tree{NewTree("a"), NewTree("b\nsecond line")}
+	})
+	root.AddTree(NewTree("go.mod"))
+
+	want := "project\n" +
+		"├── cmd\n" +
+		"│   └── main.go\n" +
+		"├── README.md\n" +
+		"├── vendor\n" +
+		"│   ├── a\n" +
+		"│   └── b\n" +
+		"│       second line\n" +
+		"└── go.mod\n"
+	for i := 0; i < 2; i++ {
+		if got := Strip(root.AsString()); got != want {
+			t.Errorf("Expected:\n%s\ngot:\n%s", want, got)
+		}
+	}
+	if lazyCalls != 1 {
+		t.Errorf("Expected the lazy children to be loaded once, got %d", lazyCalls)
+	}
+}
+
+func TestTreeStyles(t *testing.T) {
+	root := NewTree("root", Bold)
+	root.Add("failed", Red)
+	want := "\x1b[1mroot\x1b[22m\n\x1b[2m└── \x1b[22m\x1b[31mfailed\x1b[39m\n"
+	if got := root.AsString(); got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+}
+
+func TestTreeShow(t *testing.T) {
+	root := NewTree("root")
+	root.Add("a").Add("b")
+	root.Add("c")
+	got := RenderWith(ASCIITerminal, func(w io.Writer) {
+		root.SetWriter(w)
+		root.Show()
+	})
+	want := "root\n|-- a\n|   `-- b\n`-- c\n"
+	if Strip(got) != want {
+		t.Errorf("Expected:\n%s\ngot:\n%s", want, Strip(got))
+	}
+	var out bytes.Buffer
+	root.SetWriter(&out)
+	root.Show()
+	if Strip(out.String()) != Strip(root.AsString()) {
+		t.Errorf("Expected Show to write the rendered tree, got %q", out.String())
+	}
+}
+
+func TestTreeDepth(t *testing.T) {
+	loaded := false
+	root := NewTree("root")
+	a := root.Add("a")
+	a.Add("b").Children(func() []*Tree {
+		loaded = true
+		return []*Tree{NewTree("c")}
+	})
+	root.Add("d").Collapse().Add("e")
+
+	want := "root\n├── a\n│   └── b\n└── d\n"
+	if got := Strip(root.MaxDepth(2).AsString()); got != want {
+		t.Errorf("Expected:\n%s\ngot:\n%s", want, got)
+	}
+	if loaded {
+		t.Errorf("Expected children below the depth limit not to be loaded")
+	}
+	want = "root\n├── a\n└── d\n"
+	if got := Strip(root.MaxDepth(1).AsString()); got != want {
+		t.Errorf("Expected:\n%s\ngot:\n%s", want, got)
+	}
+}
+
+func TestTreeCycle(t *testing.T) {
+	root := NewTree("a")
+	b := root.Add("b")
+	b.AddTree(root)
+	want := "a\n└── b\n    └── a (cycle)\n"
+	if got := Strip(root.AsString()); got != want {
+		t.Errorf("Expected:\n%s\ngot:\n%s", want, got)
+	}
+}
@@ -0,0 +1,208 @@
+package clt
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// UpdateNotice shows a banner on os.Stderr telling the user that version latest can be
+// downloaded from url.  Nothing is shown unless latest is newer than current.  If
+// progress indicators are running, the banner is held until the last one finishes so
+// that it doesn't break up their output.  It returns true if a notice was shown or
+// queued.
+func UpdateNotice(current string, latest string, url string) bool {
+	if compareVersions(latest, current) <= 0 {
+		return false
+	}
+	showNotice(updateBanner(current, latest, url))
+	return true
+}
+
+// updateBanner renders the update notice in a box
+func updateBanner(current string, latest string, url string) string {
+	lines := []string{fmt.Sprintf("Update available: %s → %s", current, latest)}
+	if len(url) > 0 {
+		lines = append(lines, url)
+	}
+	return box(lines, CurrentTheme().Warning, Styled(Bold))
+}
+
+// UpdateCheck returns the latest released version and the url to download it from
+type UpdateCheck func() (latest string, url string, err error)
+
+// UpdateChecker runs an UpdateCheck at most once per Interval, caching the result in
+// a file so that every invocation of a command doesn't have to reach the network
+type UpdateChecker struct {
+	// Check looks up the latest version
+	Check UpdateCheck
+	// CacheFile stores the result of the last check and dismissed versions
+	CacheFile string
+	// Interval is the time between checks.  Defaults to 24 hours.
+	Interval time.Duration
+}
+
+// updateCache is the contents of the cache file
+type updateCache struct {
+	Checked   time.Time `json:"checked"`
+	Latest    string    `json:"latest"`
+	URL       string    `json:"url"`
+	Dismissed string    `json:"dismissed,omitempty"`
+}
+
+// NewUpdateChecker returns a checker that runs check and caches the result in cacheFile
+func NewUpdateChecker(check UpdateCheck, cacheFile string) *UpdateChecker {
+	return &UpdateChecker{Check: check, CacheFile: cacheFile, Interval: 24 * time.Hour}
+}
+
+// Notify shows an UpdateNotice if a version newer than current is available and it
+// hasn't been dismissed.  The check only runs when the cached result is older than
+// Interval.  Errors from the check are returned without showing a notice.
+func (u *UpdateChecker) Notify(current string) error {
+	c := u.load()
+	interval := u.Interval
+	if interval <= 0 {
+		interval = 24 * time.Hour
+	}
+	if time.Since(c.Checked) >= interval {
+		latest, url, err := u.Check()
+		if err != nil {
+			return err
+		}
+		c.Checked, c.Latest, c.URL = time.Now(), latest, url
+		if err := u.save(c); err != nil {
+			return err
+		}
+	}
+	if c.Latest == c.Dismissed {
+		return nil
+	}
+	UpdateNotice(current, c.Latest, c.URL)
+	return nil
+}
+
+// Dismiss stops notices for the latest cached version.  Notices are shown again once a
+// newer version is released.
+func (u *UpdateChecker) Dismiss() error {
+	c := u.load()
+	c.Dismissed = c.Latest
+	return u.save(c)
+}
+
+func (u *UpdateChecker) load() updateCache {
+	var c updateCache
+	if len(u.CacheFile) == 0 {
+		return c
+	}
+	data, err := os.ReadFile(u.CacheFile)
+	if err != nil {
+		return c
+	}
+	if err := json.Unmarshal(data, &c); err != nil {
+		return updateCache{}
+	}
+	return c
+}
+
+func (u *UpdateChecker) save(c updateCache) error {
+	if len(u.CacheFile) == 0 {
+		return nil
+	}
+	data, err := json.Marshal(c)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(u.CacheFile, data, 0644)
+}
+
+// compareVersions compares dotted version numbers such as v1.10.2, returning -1, 0 or
+// 1.  A leading v and any build suffix after + are ignored, and missing parts count
+// as 0.  Like semantic versioning, a pre-release such as 1.2.0-rc.1 comes before the
+// release and pre-releases are compared identifier by identifier.
+func compareVersions(a string, b string) int {
+	pa, preA := versionParts(a)
+	pb, preB := versionParts(b)
+	for len(pa) < len(pb) {
+		pa = append(pa, 0)
+	}
+	for len(pb) < len(pa) {
+		pb = append(pb, 0)
+	}
+	for i := range pa {
+		switch {
+		case pa[i] < pb[i]:
+			return -1
+		case pa[i] > pb[i]:
+			return 1
+		}
+	}
+	return comparePrerelease(preA, preB)
+}
+
+// versionParts returns the numbers of a version and its pre-release, if any
+func versionParts(v string) ([]int, string) {
+	v = strings.TrimPrefix(strings.TrimSpace(v), "v")
+	if i := strings.Index(v, "+"); i >= 0 {
+		v = v[:i]
+	}
+	var pre string
+	if i := strings.Index(v, "-"); i >= 0 {
+		v, pre = v[:i], v[i+1:]
+	}
+	var parts []int
+	for _, s := range strings.Split(v, ".") {
+		n, err := strconv.Atoi(s)
+		if err != nil {
+			n = 0
+		}
+		parts = append(parts, n)
+	}
+	return parts, pre
+}
+
+// comparePrerelease compares the pre-releases of two versions with the same numbers.
+// No pre-release comes last.  Numeric identifiers compare by value and before
+// others, which compare as text, and a shorter pre-release comes first if it is a
+// prefix of the other.
+func comparePrerelease(a string, b string) int {
+	switch {
+	case a == b:
+		return 0
+	case len(a) == 0:
+		return 1
+	case len(b) == 0:
+		return -1
+	}
+	ia, ib := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(ia) && i < len(ib); i++ {
+		na, errA := strconv.Atoi(ia[i])
+		nb, errB := strconv.Atoi(ib[i])
+		switch {
+		case errA == nil && errB == nil:
+			if na != nb {
+				if na < nb {
+					return -1
+				}
+				return 1
+			}
+		case errA == nil:
+			return -1
+		case errB == nil:
+			return 1
+		default:
+			if c := strings.Compare(ia[i], ib[i]); c != 0 {
+				return c
+			}
+		}
+	}
+	switch {
+	case len(ia) < len(ib):
+		return -1
+	case len(ia) > len(ib):
+		return 1
+	}
+	return 0
+}
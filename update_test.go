@@ -0,0 +1,97 @@
+package clt
+
+import (
+	"bytes"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCompareVersions(t *testing.T) {
+	tt := []struct {
+		a, b string
+		want int
+	}{
+		{"v1.10.0", "v1.9.3", 1},
+		{"1.2", "1.2.0", 0},
+		{"1.2.0-rc1", "1.2.1", -1},
+		{"1.2.0-rc1", "1.2.0", -1},
+		{"v1.2.0", "1.2.0-rc1", 1},
+		{"1.2.0-rc.2", "1.2.0-rc.10", -1},
+		{"1.2.0-alpha", "1.2.0-alpha.1", -1},
+		{"1.2.0-alpha.1", "1.2.0-alpha.beta", -1},
+		{"1.2.0-beta", "1.2.0-alpha", 1},
+		{"1.2.0+build.5", "1.2.0", 0},
+	}
+	for _, tc := range tt {
+		if got := compareVersions(tc.a, tc.b); got != tc.want {
+			t.Errorf("compareVersions(%q, %q): expected %d, got %d", tc.a, tc.b, tc.want, got)
+		}
+	}
+}
+
+func TestUpdateNoticeWaitsForProgress(t *testing.T) {
+	out := withNoticeOutput()
+
+	if UpdateNotice("1.2.0", "1.2.0", "") {
+		t.Errorf("Expected no notice for the current version")
+	}
+
+	p := NewProgressBar("Working")
+	p.output = bytes.NewBuffer(nil)
+	p.Start()
+	UpdateNotice("1.2.0", "1.3.0", "https://example.com/releases")
+	if out.Len() > 0 {
+		t.Errorf("Expected the notice to wait for the progress bar, got %q", out.String())
+	}
+	p.Success()
+	if !strings.Contains(out.String(), "Update available: 1.2.0 → 1.3.0") || !strings.Contains(out.String(), "https://example.com/releases") {
+		t.Errorf("Expected the notice after the progress bar finished, got %q", out.String())
+	}
+}
+
+func TestUpdateCheckerCache(t *testing.T) {
+	out := withNoticeOutput()
+	calls := 0
+	u := NewUpdateChecker(func() (string, string, error) {
+		calls++
+		return "2.0.0", "https://example.com", nil
+	}, filepath.Join(t.TempDir(), "update.json"))
+
+	if err := u.Notify("1.0.0"); err != nil {
+		t.Fatal(err)
+	}
+	if err := u.Notify("1.0.0"); err != nil {
+		t.Fatal(err)
+	}
+	if calls != 1 {
+		t.Errorf("Expected the cached result to be reused, checked %d times", calls)
+	}
+	if strings.Count(out.String(), "Update available") != 2 {
+		t.Errorf("Expected a notice for each call, got %q", out.String())
+	}
+
+	out.Reset()
+	if err := u.Dismiss(); err != nil {
+		t.Fatal(err)
+	}
+	u.Notify("1.0.0")
+	if out.Len() > 0 {
+		t.Errorf("Expected a dismissed version not to be shown, got %q", out.String())
+	}
+
+	errCheck := errors.New("offline")
+	u = NewUpdateChecker(func() (string, string, error) { return "", "", errCheck }, "")
+	if err := u.Notify("1.0.0"); err != errCheck {
+		t.Errorf("Expected %v, got %v", errCheck, err)
+	}
+}
+
+func TestUpdateBannerTheme(t *testing.T) {
+	defer SetTheme(DefaultTheme)
+	SetTheme(Theme{Warning: Styled(Magenta)})
+	if got := updateBanner("1.2.0", "1.3.0", ""); !strings.Contains(got, Styled(Magenta).ApplyTo("│")) {
+		t.Errorf("Expected the border in the theme's warning style, got %q", got)
+	}
+}
@@ -0,0 +1,51 @@
+package clt
+
+import (
+	"fmt"
+	"time"
+)
+
+// resourceUsage is the CPU and memory used by the process
+type resourceUsage struct {
+	// cpu is the percentage of one core used since the previous sample
+	cpu float64
+	rss int64
+}
+
+// String formats the usage with fixed widths so that the line doesn't jump around as
+// the numbers change
+func (u resourceUsage) String() string {
+	return fmt.Sprintf("[CPU %3.0f%% RSS %6s]", u.cpu, Bytes(u.rss))
+}
+
+// usageSampler reads the resource usage of the process at most once a second.  Must
+// be used with the mutex of its indicator held.
+type usageSampler struct {
+	last    resourceUsage
+	sampled time.Time
+	cpuTime time.Duration
+	ok      bool
+}
+
+// sample returns the latest usage, reading it again if the last sample is more than a
+// second old.  ok is false if the usage can't be read on this platform.
+func (u *usageSampler) sample() (resourceUsage, bool) {
+	now := time.Now()
+	if !u.sampled.IsZero() && now.Sub(u.sampled) < time.Second {
+		return u.last, u.ok
+	}
+	cpuTime, cpuOK := processCPUTime()
+	rss, rssOK := processRSS()
+	u.ok = cpuOK && rssOK
+	if !u.ok {
+		u.sampled = now
+		return resourceUsage{}, false
+	}
+	cpu := 0.0
+	if !u.sampled.IsZero() {
+		cpu = 100 * float64(cpuTime-u.cpuTime) / float64(now.Sub(u.sampled))
+	}
+	u.last = resourceUsage{cpu: cpu, rss: rss}
+	u.sampled, u.cpuTime = now, cpuTime
+	return u.last, true
+}
@@ -0,0 +1,22 @@
+//go:build darwin || dragonfly || freebsd || netbsd || openbsd
+
+package clt
+
+import (
+	"runtime"
+	"syscall"
+)
+
+// processRSS returns the peak resident set size of the process in bytes, since the
+// current size isn't available without cgo
+func processRSS() (int64, bool) {
+	var ru syscall.Rusage
+	if err := syscall.Getrusage(syscall.RUSAGE_SELF, &ru); err != nil {
+		return 0, false
+	}
+	// darwin reports bytes and the BSDs report kilobytes
+	if runtime.GOOS == "darwin" {
+		return int64(ru.Maxrss), true
+	}
+	return int64(ru.Maxrss) * 1024, true
+}
@@ -0,0 +1,24 @@
+package clt
+
+import (
+	"os"
+	"strconv"
+	"strings"
+)
+
+// processRSS returns the resident set size of the process in bytes
+func processRSS() (int64, bool) {
+	data, err := os.ReadFile("/proc/self/statm")
+	if err != nil {
+		return 0, false
+	}
+	fields := strings.Fields(string(data))
+	if len(fields) < 2 {
+		return 0, false
+	}
+	pages, err := strconv.ParseInt(fields[1], 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return pages * int64(os.Getpagesize()), true
+}
@@ -0,0 +1,13 @@
+//go:build !linux && !darwin && !dragonfly && !freebsd && !netbsd && !openbsd
+
+package clt
+
+import "time"
+
+func processCPUTime() (time.Duration, bool) {
+	return 0, false
+}
+
+func processRSS() (int64, bool) {
+	return 0, false
+}
@@ -64,3 +64,16 @@ func validateOptions(s string, options []string) (bool, error) {
 	}
 	return false, fmt.Errorf("%s is a not a valid option. Valid options are %v", s, options)
 }
+
+// firstError returns the error of the first validator that rejects s
+func firstError(validators []ValidationFunc, s string) string {
+	for _, validator := range validators {
+		if ok, err := validator(s); !ok {
+			if err == nil {
+				return "invalid response"
+			}
+			return err.Error()
+		}
+	}
+	return ""
+}
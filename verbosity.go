@@ -0,0 +1,158 @@
+package clt
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"time"
+)
+
+// verbosity records whether the log lines of progress indicators are shown
+var verbosity = struct {
+	sync.Mutex
+	verbose bool
+}{}
+
+// SetVerbose sets whether the lines logged with Progress.Logf are shown above the
+// running indicators.  When off, only the indicators themselves are shown.
+func SetVerbose(verbose bool) {
+	verbosity.Lock()
+	defer verbosity.Unlock()
+	verbosity.verbose = verbose
+}
+
+// Verbose returns true if the lines logged with Progress.Logf are shown
+func Verbose() bool {
+	verbosity.Lock()
+	defer verbosity.Unlock()
+	return verbosity.verbose
+}
+
+// ToggleVerbose switches between showing and hiding the lines logged with
+// Progress.Logf and returns the new setting
+func ToggleVerbose() bool {
+	verbosity.Lock()
+	verbosity.verbose = !verbosity.verbose
+	verbose := verbosity.verbose
+	verbosity.Unlock()
+
+	state := "off"
+	if verbose {
+		state = "on"
+	}
+	Println("%s", CurrentTheme().Muted.ApplyTo("verbose output "+state))
+	return verbose
+}
+
+// HandleVerbosityToggle calls ToggleVerbose each time the program receives SIGUSR2,
+// or the user presses v on keys, so that a long run that starts misbehaving can show
+// what it is doing without being restarted.  Pass os.Stdin as keys to toggle with a
+// keypress, or nil to only use the signal.  When keys is a terminal, it reads single
+// key presses without echoing them until the returned function is called, which
+// restores the terminal before it returns so that prompts can follow.  Other readers
+// are read a line at a time, where v is followed by enter, and are still read in the
+// background after stopping, so they shouldn't be used for prompts.  SIGUSR2 isn't
+// available on Windows.
+func HandleVerbosityToggle(keys io.Reader) (stop func()) {
+	c := make(chan os.Signal, 1)
+	if len(verbositySignals) > 0 {
+		signal.Notify(c, verbositySignals...)
+	}
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case <-c:
+				ToggleVerbose()
+			case <-done:
+				return
+			}
+		}
+	}()
+	// finished is closed once a terminal read a key at a time has been restored
+	var finished chan struct{}
+	if f, ok := keys.(*os.File); ok && isTerminal(f) {
+		fd := int(f.Fd())
+		if restore, err := setCbreak(fd); err == nil {
+			finished = make(chan struct{})
+			go func() {
+				defer close(finished)
+				defer restore()
+				watchVerbosityKeypress(func(timeout time.Duration) []byte { return readKeys(fd, timeout) }, done)
+			}()
+			keys = nil
+		}
+	}
+	if keys != nil {
+		go watchVerbosityKey(keys, done)
+	}
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			signal.Stop(c)
+			close(done)
+			if finished != nil {
+				<-finished
+			}
+		})
+	}
+}
+
+// keyPollInterval is how long watchVerbosityKeypress waits for a key before checking
+// whether to stop
+const keyPollInterval = 100 * time.Millisecond
+
+// watchVerbosityKeypress toggles verbosity for each v pressed, reading keys with read
+// until done is closed
+func watchVerbosityKeypress(read func(timeout time.Duration) []byte, done chan struct{}) {
+	for {
+		select {
+		case <-done:
+			return
+		default:
+		}
+		for _, b := range read(keyPollInterval) {
+			if b == 'v' || b == 'V' {
+				ToggleVerbose()
+			}
+		}
+	}
+}
+
+// watchVerbosityKey toggles verbosity for each line of keys that is a v, for readers
+// that aren't terminals
+func watchVerbosityKey(keys io.Reader, done chan struct{}) {
+	scanner := bufio.NewScanner(keys)
+	for scanner.Scan() {
+		select {
+		case <-done:
+			return
+		default:
+		}
+		if strings.EqualFold(strings.TrimSpace(scanner.Text()), "v") {
+			ToggleVerbose()
+		}
+	}
+}
+
+// Logf logs a line about the work of the indicator.  In verbose mode, the line is
+// printed above the running indicators, labelled with the prompt of this indicator.
+// Otherwise it is discarded, so that detailed output can be left in place and turned
+// on with SetVerbose or HandleVerbosityToggle when it is needed.
+func (p *Progress) Logf(format string, args ...interface{}) {
+	if !Verbose() {
+		return
+	}
+	p.mtx.Lock()
+	label := strings.TrimSpace(p.Prompt)
+	p.mtx.Unlock()
+	text := fmt.Sprintf(format, args...)
+	if len(label) > 0 {
+		text = CurrentTheme().Muted.ApplyTo(label+":") + " " + text
+	}
+	p.Println("%s", text)
+}
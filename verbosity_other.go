@@ -0,0 +1,8 @@
+//go:build !linux && !darwin && !dragonfly && !freebsd && !netbsd && !openbsd
+
+package clt
+
+import "os"
+
+// verbositySignals is empty where there is no SIGUSR2
+var verbositySignals []os.Signal
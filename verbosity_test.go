@@ -0,0 +1,65 @@
+package clt
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLogf(t *testing.T) {
+	defer SetVerbose(false)
+	out := new(bytes.Buffer)
+	p := NewProgressSpinner("Syncing", WithProgressOutput(out))
+
+	SetVerbose(false)
+	p.Logf("fetched %d objects", 12)
+	if out.Len() > 0 {
+		t.Errorf("expected nothing to be logged when not verbose, got %q", out.String())
+	}
+
+	SetVerbose(true)
+	p.Logf("fetched %d objects", 12)
+	if want := Styled(Dim).ApplyTo("Syncing:") + " fetched 12 objects\n"; out.String() != want {
+		t.Errorf("expected %q, got %q", want, out.String())
+	}
+}
+
+func TestVerbosityKey(t *testing.T) {
+	defer SetVerbose(false)
+	SetVerbose(false)
+	watchVerbosityKey(strings.NewReader("v\nx\nV\n"), make(chan struct{}))
+	if Verbose() {
+		t.Errorf("expected two toggles to leave verbose output off")
+	}
+	watchVerbosityKey(strings.NewReader(" v \n"), make(chan struct{}))
+	if !Verbose() {
+		t.Errorf("expected verbose output to be toggled on")
+	}
+}
+
+func TestVerbosityKeypress(t *testing.T) {
+	defer SetVerbose(false)
+	SetVerbose(false)
+	done := make(chan struct{})
+	presses := [][]byte{[]byte("x"), nil, []byte("v"), []byte("Vv")}
+	reads := 0
+	read := func(timeout time.Duration) []byte {
+		if timeout != keyPollInterval {
+			t.Errorf("Expected to wait %s for a key, got %s", keyPollInterval, timeout)
+		}
+		if reads == len(presses) {
+			close(done)
+			return nil
+		}
+		reads++
+		return presses[reads-1]
+	}
+	watchVerbosityKeypress(read, done)
+	if !Verbose() {
+		t.Errorf("expected three presses of v to leave verbose output on")
+	}
+	if reads != len(presses) {
+		t.Errorf("expected %d reads before stopping, got %d", len(presses), reads)
+	}
+}
@@ -0,0 +1,11 @@
+//go:build linux || darwin || dragonfly || freebsd || netbsd || openbsd
+
+package clt
+
+import (
+	"os"
+	"syscall"
+)
+
+// verbositySignals toggle verbose output with HandleVerbosityToggle
+var verbositySignals = []os.Signal{syscall.SIGUSR2}
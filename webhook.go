@@ -0,0 +1,171 @@
+package clt
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Webhook is a Display that posts progress announcements to a Slack-compatible
+// webhook, so that long unattended runs can report their status off the terminal.
+// Starts, results, and milestone percentages are announced.  Announcements are
+// batched so that at most one post is made per Interval.  Add it to indicators with
+// Mirror or MirrorAll, and call Flush before the program exits.
+type Webhook struct {
+	// URL receives a POST of {"text": "..."} for each batch of announcements
+	URL string
+	// Milestones are the fractions complete that are announced for bars.  Defaults
+	// to 25%, 50% and 75%.
+	Milestones []float64
+	// Interval is the minimum time between posts.  Defaults to 10 seconds.
+	Interval time.Duration
+	// Client sends the posts.  Defaults to http.DefaultClient.
+	Client *http.Client
+
+	// sending is held for each post so that batches are posted one at a time and in
+	// order, and Flush waits for a post in flight
+	sending sync.Mutex
+	mtx     sync.Mutex
+	pending []string
+	reached map[string]float64
+	last    time.Time
+	timer   *time.Timer
+	err     error
+}
+
+// NewWebhook returns a webhook display that posts to url
+func NewWebhook(url string) *Webhook {
+	return &Webhook{
+		URL:        url,
+		Milestones: []float64{0.25, 0.5, 0.75},
+		Interval:   10 * time.Second,
+	}
+}
+
+// Render queues an announcement for events worth reporting
+func (w *Webhook) Render(e Event) {
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+	if w.reached == nil {
+		w.reached = make(map[string]float64)
+	}
+
+	var msg string
+	switch e.Type {
+	case EventStart:
+		msg = fmt.Sprintf("▶ %s started", e.Prompt)
+		w.reached[e.Prompt] = 0
+	case EventSuccess:
+		msg = fmt.Sprintf("✓ %s finished", e.Prompt)
+		delete(w.reached, e.Prompt)
+	case EventFail:
+		msg = fmt.Sprintf("✗ %s failed", e.Prompt)
+		delete(w.reached, e.Prompt)
+	case EventFinish:
+		msg = fmt.Sprintf("• %s: %s", e.Prompt, e.Message)
+		delete(w.reached, e.Prompt)
+	case EventTick:
+		// only the highest milestone passed since the last announcement is reported
+		var passed float64
+		for _, m := range w.Milestones {
+			if e.Pct >= m && m > w.reached[e.Prompt] && m > passed {
+				passed = m
+			}
+		}
+		if passed == 0 {
+			return
+		}
+		w.reached[e.Prompt] = passed
+		msg = fmt.Sprintf("%s: %.0f%%", e.Prompt, 100*passed)
+	default:
+		return
+	}
+	w.pending = append(w.pending, msg)
+	w.schedule()
+}
+
+// schedule sends the pending announcements once Interval has passed since the last
+// post.  Must be called with the mutex held.
+func (w *Webhook) schedule() {
+	if w.timer != nil {
+		return
+	}
+	interval := w.Interval
+	if interval <= 0 {
+		interval = 10 * time.Second
+	}
+	delay := time.Until(w.last.Add(interval))
+	if delay < 0 {
+		delay = 0
+	}
+	w.timer = time.AfterFunc(delay, func() {
+		w.send()
+	})
+}
+
+// Flush waits for a post in flight, posts any pending announcements immediately and
+// returns the error from the last post that failed, if any
+func (w *Webhook) Flush() error {
+	w.mtx.Lock()
+	if w.timer != nil {
+		w.timer.Stop()
+	}
+	w.mtx.Unlock()
+	w.send()
+
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+	err := w.err
+	w.err = nil
+	return err
+}
+
+// send posts the pending announcements as one message after any post in flight
+func (w *Webhook) send() {
+	w.sending.Lock()
+	defer w.sending.Unlock()
+	w.mtx.Lock()
+	w.timer = nil
+	if len(w.pending) == 0 {
+		w.mtx.Unlock()
+		return
+	}
+	text := strings.Join(w.pending, "\n")
+	w.pending = nil
+	w.last = time.Now()
+	client := w.Client
+	w.mtx.Unlock()
+
+	if client == nil {
+		client = http.DefaultClient
+	}
+	err := w.post(client, text)
+
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+	if err != nil {
+		w.err = err
+	}
+}
+
+func (w *Webhook) post(client *http.Client, text string) error {
+	body, err := json.Marshal(struct {
+		Text string `json:"text"`
+	}{text})
+	if err != nil {
+		return err
+	}
+	resp, err := client.Post(w.URL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned %s", resp.Status)
+	}
+	return nil
+}
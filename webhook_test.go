@@ -0,0 +1,106 @@
+package clt
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestWebhook(t *testing.T) {
+	var mtx sync.Mutex
+	var posts []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct{ Text string }
+		json.NewDecoder(r.Body).Decode(&body)
+		mtx.Lock()
+		posts = append(posts, body.Text)
+		mtx.Unlock()
+	}))
+	defer srv.Close()
+
+	hook := NewWebhook(srv.URL)
+	p := NewProgressBar("Uploading")
+	p.output = bytes.NewBuffer(nil)
+	p.Mirror(hook)
+	p.Start()
+	// the first post is sent right away and the rest wait for the interval
+	if err := hook.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	p.Update(0.3)
+	p.Update(0.4)
+	p.Update(0.8)
+	p.Success()
+	if err := hook.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	mtx.Lock()
+	defer mtx.Unlock()
+	want := []string{"▶ Uploading started", "Uploading: 25%\nUploading: 75%\n✓ Uploading finished"}
+	if len(posts) != len(want) {
+		t.Fatalf("Expected %d posts, got %q", len(want), posts)
+	}
+	for i := range want {
+		if posts[i] != want[i] {
+			t.Errorf("Expected post %q, got %q", want[i], posts[i])
+		}
+	}
+}
+
+func TestWebhookError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer srv.Close()
+
+	hook := NewWebhook(srv.URL)
+	hook.Render(Event{Type: EventStart, Prompt: "Deploying"})
+	if err := hook.Flush(); err == nil {
+		t.Errorf("Expected an error from a rejected post")
+	}
+}
+
+func TestWebhookFlushWaits(t *testing.T) {
+	var mtx sync.Mutex
+	var posts []string
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct{ Text string }
+		json.NewDecoder(r.Body).Decode(&body)
+		if body.Text == "▶ Deploying started" {
+			<-release
+		}
+		mtx.Lock()
+		posts = append(posts, body.Text)
+		mtx.Unlock()
+	}))
+	defer srv.Close()
+
+	hook := NewWebhook(srv.URL)
+	hook.Interval = time.Hour
+	// the first post is sent right away by the timer and held by the server
+	hook.Render(Event{Type: EventStart, Prompt: "Deploying"})
+	time.Sleep(50 * time.Millisecond)
+	hook.Render(Event{Type: EventSuccess, Prompt: "Deploying"})
+	time.AfterFunc(50*time.Millisecond, func() { close(release) })
+	if err := hook.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	mtx.Lock()
+	defer mtx.Unlock()
+	want := []string{"▶ Deploying started", "✓ Deploying finished"}
+	if len(posts) != len(want) {
+		t.Fatalf("Expected Flush to wait for %d posts, got %q", len(want), posts)
+	}
+	for i := range want {
+		if posts[i] != want[i] {
+			t.Errorf("Expected post %q, got %q", want[i], posts[i])
+		}
+	}
+}
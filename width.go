@@ -0,0 +1,299 @@
+package clt
+
+import (
+	"os"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// wideRanges are the code points that take two columns in a terminal: East Asian wide
+// and fullwidth characters and emoji
+var wideRanges = []struct{ lo, hi rune }{
+	{0x1100, 0x115f},
+	{0x231a, 0x231b},
+	{0x23e9, 0x23ec},
+	{0x23f0, 0x23f0},
+	{0x23f3, 0x23f3},
+	{0x25fd, 0x25fe},
+	{0x2614, 0x2615},
+	{0x2648, 0x2653},
+	{0x26a1, 0x26a1},
+	{0x26aa, 0x26ab},
+	{0x26bd, 0x26be},
+	{0x26c4, 0x26c5},
+	{0x26ce, 0x26ce},
+	{0x26d4, 0x26d4},
+	{0x26ea, 0x26ea},
+	{0x26f2, 0x26f5},
+	{0x26fa, 0x26fd},
+	{0x2705, 0x2705},
+	{0x270a, 0x270b},
+	{0x2728, 0x2728},
+	{0x274c, 0x274c},
+	{0x2753, 0x2755},
+	{0x2757, 0x2757},
+	{0x2795, 0x2797},
+	{0x27b0, 0x27b0},
+	{0x27bf, 0x27bf},
+	{0x2b1b, 0x2b1c},
+	{0x2b50, 0x2b50},
+	{0x2b55, 0x2b55},
+	{0x2e80, 0x303e},
+	{0x3041, 0x33ff},
+	{0x3400, 0x4dbf},
+	{0x4e00, 0x9fff},
+	{0xa000, 0xa4cf},
+	{0xa960, 0xa97f},
+	{0xac00, 0xd7a3},
+	{0xf900, 0xfaff},
+	{0xfe10, 0xfe19},
+	{0xfe30, 0xfe6f},
+	{0xff00, 0xff60},
+	{0xffe0, 0xffe6},
+	{0x16fe0, 0x18aff},
+	{0x1b000, 0x1b2ff},
+	{0x1f004, 0x1f004},
+	{0x1f0cf, 0x1f0cf},
+	{0x1f18e, 0x1f18e},
+	{0x1f191, 0x1f19a},
+	{0x1f200, 0x1f251},
+	{0x1f300, 0x1f64f},
+	{0x1f680, 0x1f6ff},
+	{0x1f7e0, 0x1f7eb},
+	{0x1f90c, 0x1f9ff},
+	{0x1fa70, 0x1faff},
+	{0x20000, 0x3fffd},
+}
+
+// runeWidth returns the number of terminal columns taken by r
+func runeWidth(r rune) int {
+	switch {
+	case r == 0:
+		return 0
+	case r < 0x20 || r == 0x7f:
+		return 0
+	case r < 0x300:
+		return 1
+	case r == 0x200d, r >= 0xfe00 && r <= 0xfe0f:
+		// zero width joiner and variation selectors
+		return 0
+	case unicode.In(r, unicode.Mn, unicode.Me, unicode.Cf):
+		return 0
+	}
+	for _, w := range wideRanges {
+		if r < w.lo {
+			break
+		}
+		if r <= w.hi {
+			return 2
+		}
+	}
+	return 1
+}
+
+// displayWidth returns the number of terminal columns taken by s, counting wide
+// characters such as CJK and emoji as two columns and combining marks as none
+func displayWidth(s string) int {
+	n := 0
+	for _, r := range s {
+		n += runeWidth(r)
+	}
+	return n
+}
+
+// Strip returns s without ANSI escape sequences such as the styles added by Styled
+func Strip(s string) string {
+	var b strings.Builder
+	w := &plainWriter{w: &b}
+	w.Write([]byte(s))
+	return b.String()
+}
+
+// VisibleWidth returns the number of terminal columns taken by s when it is printed.
+// Escape sequences take no columns, wide characters such as CJK and emoji take two
+// and combining marks take none.
+func VisibleWidth(s string) int {
+	return displayWidth(Strip(s))
+}
+
+// Truncate shortens s to at most n columns, ending with an ellipsis when characters
+// are removed.  Escape sequences are never split, and those after the cut are kept
+// so that styles opened before it are still closed.
+func Truncate(s string, n int) string {
+	switch {
+	case VisibleWidth(s) <= n:
+		return s
+	case n <= 0:
+		return ""
+	}
+	var b strings.Builder
+	w := 0
+	cut := false
+	for len(s) > 0 {
+		if l := escapeLength(s); l > 0 {
+			b.WriteString(s[:l])
+			s = s[l:]
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s)
+		s = s[size:]
+		// leave a column for the ellipsis
+		if cut || w+runeWidth(r) > n-1 {
+			if !cut {
+				b.WriteString("…")
+				cut = true
+			}
+			continue
+		}
+		w += runeWidth(r)
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// Wrap breaks s into lines of at most width columns, breaking at spaces where it can
+// and splitting words that are longer than a line.  Escape sequences take no columns
+// and are never split, and styles that carry over a break are ended at the end of the
+// line and started again on the next, so that each line can be indented on its own.
+// A width of 0 or less wraps to the width of the terminal, or 80 columns if standard
+// output isn't one.
+func Wrap(s string, width int) string {
+	if width <= 0 {
+		width = terminalWidth(os.Stdout)
+	}
+	if width <= 0 {
+		width = 80
+	}
+	var lines []string
+	for _, line := range strings.Split(s, "\n") {
+		lines = append(lines, wrapLine(line, width)...)
+	}
+	return strings.Join(restyleLines(lines), "\n")
+}
+
+// wrapLine wraps a line without line breaks
+func wrapLine(line string, width int) []string {
+	var lines []string
+	var cur strings.Builder
+	w := 0
+	for i, word := range strings.Split(line, " ") {
+		ww := VisibleWidth(word)
+		switch {
+		case i == 0:
+		case w+1+ww <= width:
+			cur.WriteString(" ")
+			w++
+		default:
+			lines = append(lines, cur.String())
+			cur.Reset()
+			w = 0
+		}
+		for w+ww > width {
+			// a word longer than the rest of the line is split at the edge
+			head, rest := splitColumns(word, width-w)
+			if w > 0 && len(Strip(head)) == 0 {
+				// not even one character fits after what is already on the line
+				head, rest = "", word
+			}
+			cur.WriteString(head)
+			lines = append(lines, cur.String())
+			cur.Reset()
+			word, ww, w = rest, VisibleWidth(rest), 0
+		}
+		cur.WriteString(word)
+		w += ww
+	}
+	return append(lines, cur.String())
+}
+
+// splitColumns splits s after at most n columns without splitting escape sequences.
+// At least one character is kept in head so that splitting always makes progress.
+func splitColumns(s string, n int) (head string, rest string) {
+	w := 0
+	for i := 0; i < len(s); {
+		if l := escapeLength(s[i:]); l > 0 {
+			i += l
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if w+runeWidth(r) > n && w > 0 {
+			return s[:i], s[i:]
+		}
+		w += runeWidth(r)
+		i += size
+	}
+	return s, ""
+}
+
+// restyleLines ends the styles still in effect at the end of each line and starts them
+// again at the beginning of the next
+func restyleLines(lines []string) []string {
+	var state sgrState
+	for n, line := range lines {
+		start := sgrTransition(sgrState{}, state)
+		for i := 0; i < len(line); {
+			l := escapeLength(line[i:])
+			if l == 0 {
+				i++
+				continue
+			}
+			if seq := line[i : i+l]; l > 2 && seq[1] == '[' && seq[l-1] == 'm' {
+				state.apply(seq[2 : l-1])
+			}
+			i += l
+		}
+		end := ""
+		if state != (sgrState{}) {
+			end = "\x1b[0m"
+		}
+		lines[n] = start + line + end
+	}
+	return lines
+}
+
+// splitLines splits s at line breaks
+func splitLines(s string) []string {
+	return strings.Split(strings.Replace(s, "\r\n", "\n", -1), "\n")
+}
+
+// Indent adds prefix to the start of every line of s that isn't empty, such as to
+// nest wrapped text under a heading
+func Indent(s string, prefix string) string {
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		if len(line) > 0 {
+			lines[i] = prefix + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// escapeLength returns the length of the CSI or OSC escape sequence at the start of
+// s, or 0 if s doesn't start with one
+func escapeLength(s string) int {
+	if len(s) < 2 || s[0] != 0x1b {
+		return 0
+	}
+	switch s[1] {
+	case '[':
+		for i := 2; i < len(s); i++ {
+			if s[i] >= 0x40 && s[i] <= 0x7e {
+				return i + 1
+			}
+		}
+	case ']':
+		// ended by BEL or ESC \
+		for i := 2; i < len(s); i++ {
+			switch {
+			case s[i] == 0x07:
+				return i + 1
+			case s[i] == 0x1b && i+1 < len(s) && s[i+1] == '\\':
+				return i + 2
+			}
+		}
+	default:
+		return 2
+	}
+	return len(s)
+}
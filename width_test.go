@@ -0,0 +1,108 @@
+package clt
+
+import "testing"
+
+func TestDisplayWidth(t *testing.T) {
+	tt := []struct {
+		s    string
+		want int
+	}{
+		{"Upload", 6},
+		{"上传文件", 8},
+		{"🕐 ", 3},
+		{"⠋", 1},
+		{"é", 1},
+		{"🚀✨", 4},
+	}
+	for _, tc := range tt {
+		if got := displayWidth(tc.s); got != tc.want {
+			t.Errorf("displayWidth(%q): expected %d, got %d", tc.s, tc.want, got)
+		}
+	}
+}
+
+func TestTruncateWide(t *testing.T) {
+	if got := truncate("上传文件", 5); got != "上传…" {
+		t.Errorf("Expected a wide prompt to be cut to fit the columns, got %q", got)
+	}
+	if got := marquee("上传文件", 3, 0); got != "上" {
+		t.Errorf("Expected a window of at most 3 columns, got %q", got)
+	}
+}
+
+func TestStrip(t *testing.T) {
+	tt := []struct {
+		s    string
+		want string
+	}{
+		{SStyled("OK", Green, Bold), "OK"},
+		{"\x1b]8;;https://example.com\x07link\x1b]8;;\x07", "link"},
+		{"plain", "plain"},
+	}
+	for _, tc := range tt {
+		if got := Strip(tc.s); got != tc.want {
+			t.Errorf("Strip(%q): expected %q, got %q", tc.s, tc.want, got)
+		}
+	}
+	if got := VisibleWidth(SStyled("上传", Red) + " ok"); got != 7 {
+		t.Errorf("Expected the styled string to take 7 columns, got %d", got)
+	}
+}
+
+func TestTruncateStyled(t *testing.T) {
+	tt := []struct {
+		s    string
+		n    int
+		want string
+	}{
+		{SStyled("Uploading", Green), 5, "\x1b[32mUplo…\x1b[39m"},
+		{SStyled("OK", Green), 5, SStyled("OK", Green)},
+		{"ab" + SStyled("cd", Red) + "ef", 4, "ab\x1b[31mc…\x1b[39m"},
+		{SStyled("上传文件", Bold), 5, "\x1b[1m上传…\x1b[22m"},
+		{SStyled("x", Red), 0, ""},
+	}
+	for _, tc := range tt {
+		got := Truncate(tc.s, tc.n)
+		if got != tc.want {
+			t.Errorf("Truncate(%q, %d): expected %q, got %q", tc.s, tc.n, tc.want, got)
+		}
+		if w := VisibleWidth(got); w > tc.n {
+			t.Errorf("Truncate(%q, %d): result takes %d columns", tc.s, tc.n, w)
+		}
+	}
+}
+
+func TestWrap(t *testing.T) {
+	red, end := "\x1b[31m", "\x1b[39m"
+	tt := []struct {
+		Name  string
+		In    string
+		Width int
+		Want  string
+	}{
+		{Name: "fits", In: "short line", Width: 20, Want: "short line"},
+		{Name: "words", In: "the quick brown fox jumps", Width: 10, Want: "the quick\nbrown fox\njumps"},
+		{Name: "long word", In: "a supercalifragilistic word", Width: 8, Want: "a\nsupercal\nifragili\nstic\nword"},
+		{Name: "newlines", In: "one two\n\nthree four", Width: 7, Want: "one two\n\nthree\nfour"},
+		{Name: "styled", In: red + "red words" + end + " and more", Width: 9, Want: red + "red words" + end + "\nand more"},
+		{Name: "style over break", In: red + "red words here" + end, Width: 9, Want: red + "red words\x1b[0m\n" + red + "here" + end},
+		{Name: "wide", In: "上传文件 ok", Width: 5, Want: "上传\n文件\nok"},
+	}
+	for _, tc := range tt {
+		t.Run(tc.Name, func(t *testing.T) {
+			if got := Wrap(tc.In, tc.Width); got != tc.Want {
+				t.Errorf("Expected %q, got %q", tc.Want, got)
+			}
+		})
+	}
+}
+
+func TestIndent(t *testing.T) {
+	got := Indent(Wrap("\x1b[1mbold text\x1b[22m", 4), "  ")
+	if want := "  \x1b[1mbold\x1b[0m\n  \x1b[1mtext\x1b[22m"; got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+	if got := Indent("a\n\nb\n", "> "); got != "> a\n\n> b\n" {
+		t.Errorf("Expected empty lines to be left alone, got %q", got)
+	}
+}